@@ -0,0 +1,151 @@
+package soap
+
+import (
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Namespaces of the WS-Addressing headers.
+const (
+	// WSAddressingNamespace is the namespace of WS-Addressing 1.0.
+	WSAddressingNamespace = "http://www.w3.org/2005/08/addressing"
+	// WSAddressing200408Namespace is the namespace of the August 2004
+	// submission, still required by some WCF bindings.
+	WSAddressing200408Namespace = "http://schemas.xmlsoap.org/ws/2004/08/addressing"
+)
+
+// Addressing struct configures the WS-Addressing headers added to the
+// request envelope. To and Action default to the URL and the SOAPAction of
+// the request, and a new MessageID is generated for each call.
+//
+//	client.SetAddressing(&soap.Addressing{
+//		Namespace: soap.WSAddressing200408Namespace,
+//		FaultTo:   "http://client.example.com/faults",
+//	})
+type Addressing struct {
+	// Namespace defaults to WSAddressingNamespace.
+	Namespace string
+	To        string
+	Action    string
+	MessageID string
+	// ReplyTo defaults to the anonymous address with the 2004/08 namespace,
+	// the default of WS-Addressing 1.0 being implied.
+	ReplyTo string
+	FaultTo string
+	// MustUnderstand marks the To and Action headers as mandatory.
+	MustUnderstand bool
+}
+
+type addressingHeader struct {
+	XMLName xml.Name
+	Wsa     string     `xml:"xmlns:wsa,attr"`
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Value   string     `xml:",chardata"`
+}
+
+type endpointReference struct {
+	XMLName xml.Name
+	Wsa     string `xml:"xmlns:wsa,attr"`
+	Address string `xml:"wsa:Address"`
+}
+
+// namespace returns the WS-Addressing namespace, with its default.
+func (a *Addressing) namespace() string {
+	if a.Namespace == "" {
+		return WSAddressingNamespace
+	}
+	return a.Namespace
+}
+
+// anonymous returns the anonymous address of the namespace.
+func (a *Addressing) anonymous() string {
+	if a.namespace() == WSAddressing200408Namespace {
+		return WSAddressing200408Namespace + "/role/anonymous"
+	}
+	return WSAddressingNamespace + "/anonymous"
+}
+
+// header returns the header entries of the message sent to url with the
+// action and message id, in an envelope whose namespace prefix is prefix.
+func (a *Addressing) header(prefix, namespace, to, action, messageID string) ([]byte, error) {
+	ns := a.namespace()
+	var mustUnderstand []xml.Attr
+	if a.MustUnderstand {
+		if prefix == "" {
+			prefix = "soapenv"
+			mustUnderstand = append(mustUnderstand, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: namespace})
+		}
+		mustUnderstand = append(mustUnderstand, xml.Attr{Name: xml.Name{Local: prefix + ":mustUnderstand"}, Value: "1"})
+	}
+
+	entries := []interface{}{
+		&addressingHeader{XMLName: xml.Name{Local: "wsa:Action"}, Wsa: ns, Attrs: mustUnderstand, Value: action},
+		&addressingHeader{XMLName: xml.Name{Local: "wsa:MessageID"}, Wsa: ns, Value: messageID},
+	}
+	replyTo := a.ReplyTo
+	if replyTo == "" && ns == WSAddressing200408Namespace {
+		replyTo = a.anonymous()
+	}
+	if replyTo != "" {
+		entries = append(entries, &endpointReference{XMLName: xml.Name{Local: "wsa:ReplyTo"}, Wsa: ns, Address: replyTo})
+	}
+	if a.FaultTo != "" {
+		entries = append(entries, &endpointReference{XMLName: xml.Name{Local: "wsa:FaultTo"}, Wsa: ns, Address: a.FaultTo})
+	}
+	entries = append(entries, &addressingHeader{XMLName: xml.Name{Local: "wsa:To"}, Wsa: ns, Attrs: mustUnderstand, Value: to})
+
+	var data []byte
+	for _, entry := range entries {
+		b, err := xml.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, b...)
+	}
+	return data, nil
+}
+
+// address adds the WS-Addressing headers to the marshaled envelope.
+func (a *Addressing) address(envelope []byte, to, action, messageID string) ([]byte, error) {
+	prefix, namespace, err := envelopeName(envelope)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := a.header(prefix, namespace, to, action, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return insertHeader(envelope, entries)
+}
+
+// newMessageID returns a random "urn:uuid:" message identifier.
+func newMessageID() (string, error) {
+	u := make([]byte, 16)
+	if _, err := rand.Read(u); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
+
+// relatesTo returns the wsa:RelatesTo header of the envelope, of any
+// WS-Addressing namespace.
+func relatesTo(envelope []byte) string {
+	root, err := parseXML(envelope)
+	if err != nil {
+		return ""
+	}
+	header := root.child(root.namespace(), "Header")
+	if header == nil {
+		return ""
+	}
+	for _, ns := range []string{WSAddressingNamespace, WSAddressing200408Namespace} {
+		if e := header.child(ns, "RelatesTo"); e != nil {
+			return strings.TrimSpace(e.text())
+		}
+	}
+	return ""
+}
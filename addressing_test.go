@@ -0,0 +1,174 @@
+package soap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestRequest_Call_Addressing(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		root, _ := parseXML(received)
+		messageID := root.find(func(e *xmlElement) bool { return e.local == "MessageID" })
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Header>` +
+			`<wsa:RelatesTo xmlns:wsa="` + messageID.namespace() + `">` + messageID.text() + `</wsa:RelatesTo>` +
+			`</soap:Header><soap:Body/></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		version        SOAPVersion
+		addressing     *Addressing
+		security       *WSSecurity
+		wantNamespace  string
+		wantHeaders    map[string]string
+		mustUnderstand bool
+	}{
+		{
+			name:          "WS-Addressing 1.0 defaults",
+			version:       SOAP11,
+			addressing:    &Addressing{},
+			wantNamespace: WSAddressingNamespace,
+			wantHeaders:   map[string]string{"Action": "urn:NumberToWords", "To": server.URL},
+		},
+		{
+			name:          "2004/08 anonymous ReplyTo",
+			version:       SOAP11,
+			addressing:    &Addressing{Namespace: WSAddressing200408Namespace},
+			wantNamespace: WSAddressing200408Namespace,
+			wantHeaders: map[string]string{
+				"Action":  "urn:NumberToWords",
+				"To":      server.URL,
+				"ReplyTo": WSAddressing200408Namespace + "/role/anonymous",
+			},
+		},
+		{
+			name:    "Explicit headers",
+			version: SOAP12,
+			addressing: &Addressing{
+				To:             "urn:service",
+				Action:         "urn:action",
+				MessageID:      "urn:uuid:1",
+				ReplyTo:        "http://client/replies",
+				FaultTo:        "http://client/faults",
+				MustUnderstand: true,
+			},
+			wantNamespace: WSAddressingNamespace,
+			wantHeaders: map[string]string{
+				"Action":    "urn:action",
+				"To":        "urn:service",
+				"MessageID": "urn:uuid:1",
+				"ReplyTo":   "http://client/replies",
+				"FaultTo":   "http://client/faults",
+			},
+			mustUnderstand: true,
+		},
+		{
+			name:          "Along with WS-Security",
+			version:       SOAP11,
+			addressing:    &Addressing{},
+			security:      &WSSecurity{UsernameToken: &UsernameToken{Username: "user"}},
+			wantNamespace: WSAddressingNamespace,
+			wantHeaders:   map[string]string{"Action": "urn:NumberToWords", "To": server.URL},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New().R().
+				SetUrl(server.URL).
+				SetSOAPVersion(tt.version).
+				SetSOAPAction("urn:NumberToWords").
+				SetAddressing(tt.addressing).
+				SetWSSecurity(tt.security).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"})
+			resp, err := r.Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+
+			root, err := parseXML(received)
+			if err != nil {
+				t.Fatalf("parseXML() error = %v", err)
+			}
+			header := root.child(root.namespace(), "Header")
+			if header == nil {
+				t.Fatalf("no Header in %s", received)
+			}
+			for local, want := range tt.wantHeaders {
+				e := header.child(tt.wantNamespace, local)
+				if e == nil {
+					t.Errorf("no wsa:%s in %s", local, received)
+					continue
+				}
+				if got := e.text(); got != want {
+					t.Errorf("wsa:%s = %v, want %v", local, got, want)
+				}
+			}
+			if got, _ := header.child(tt.wantNamespace, "Action").attr(tt.version.Namespace(), "mustUnderstand"); (got == "1") != tt.mustUnderstand {
+				t.Errorf("wsa:Action mustUnderstand = %q, want %v", got, tt.mustUnderstand)
+			}
+			if tt.security != nil && header.child(WSSENamespace, "Security") == nil {
+				t.Errorf("no wsse:Security in %s", received)
+			}
+
+			if r.MessageID() == "" || header.child(tt.wantNamespace, "MessageID").text() != r.MessageID() {
+				t.Errorf("MessageID() = %v, want the sent wsa:MessageID", r.MessageID())
+			}
+			if got := resp.RelatesTo(); got != r.MessageID() {
+				t.Errorf("RelatesTo() = %v, want %v", got, r.MessageID())
+			}
+		})
+	}
+}
+
+func Test_newMessageID(t *testing.T) {
+	id, err := newMessageID()
+	if err != nil {
+		t.Fatalf("newMessageID() error = %v", err)
+	}
+	if !regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("newMessageID() = %v, want a random urn:uuid", id)
+	}
+	if other, _ := newMessageID(); other == id {
+		t.Errorf("newMessageID() = %v twice", id)
+	}
+}
+
+func Test_relatesTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope string
+		want     string
+	}{
+		{
+			name:     "WS-Addressing 1.0",
+			envelope: `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Header><a:RelatesTo xmlns:a="` + WSAddressingNamespace + `"> urn:uuid:1 </a:RelatesTo></s:Header><s:Body/></s:Envelope>`,
+			want:     "urn:uuid:1",
+		},
+		{
+			name:     "2004/08",
+			envelope: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Header><RelatesTo xmlns="` + WSAddressing200408Namespace + `">urn:uuid:2</RelatesTo></s:Header><s:Body/></s:Envelope>`,
+			want:     "urn:uuid:2",
+		},
+		{
+			name:     "No header",
+			envelope: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body/></s:Envelope>`,
+		},
+		{
+			name:     "Not XML",
+			envelope: `Bad Gateway`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relatesTo([]byte(tt.envelope)); got != tt.want {
+				t.Errorf("relatesTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
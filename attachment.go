@@ -0,0 +1,50 @@
+package soap
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"strings"
+)
+
+// Attachment struct is a MIME part sent or received along with the envelope
+// in a SOAP with Attachments `multipart/related` message. The envelope
+// refers to it with a `cid:` URI of its ContentID.
+type Attachment struct {
+	// ContentID is the identifier of the part, without angle brackets.
+	ContentID   string
+	ContentType string
+	// Header holds the MIME headers of a received part.
+	Header  textproto.MIMEHeader
+	Content io.Reader
+}
+
+// part reads the attachment content into a MIME part.
+func (a *Attachment) part() (*mimePart, error) {
+	var data []byte
+	if a.Content != nil {
+		var err error
+		if data, err = ioutil.ReadAll(a.Content); err != nil {
+			return nil, err
+		}
+	}
+	header := textproto.MIMEHeader{}
+	for k, v := range a.Header {
+		header[k] = v
+	}
+	if a.ContentType != "" {
+		header.Set("Content-Type", a.ContentType)
+	}
+	return &mimePart{contentID: strings.Trim(a.ContentID, "<>"), header: header, data: data}, nil
+}
+
+// attachment returns the received part as an attachment.
+func (p *mimePart) attachment() *Attachment {
+	return &Attachment{
+		ContentID:   p.contentID,
+		ContentType: p.header.Get("Content-Type"),
+		Header:      p.header,
+		Content:     bytes.NewReader(p.data),
+	}
+}
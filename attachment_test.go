@@ -0,0 +1,108 @@
+package soap
+
+import (
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequest_Call_Attachments(t *testing.T) {
+	var mediaType string
+	var params map[string]string
+	var received []*mimePart
+	var envelope []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+		mediaType, params, _ = mime.ParseMediaType(contentType)
+		body, _ := ioutil.ReadAll(r.Body)
+		var err error
+		if envelope, received, err = readMultipart(contentType, body); err != nil {
+			t.Errorf("readMultipart() error = %v", err)
+		}
+
+		response, _ := marshalEnvelope(SOAP11, &NumberToWordsResponse{NumberToWordsResult: "cid:receipt@service"})
+		data, responseType, err := writeMultipart(SOAP11, "", response, []*mimePart{
+			{contentID: "receipt@service", header: textproto.MIMEHeader{"Content-Type": {"text/plain"}}, data: []byte("received")},
+		}, false)
+		if err != nil {
+			t.Errorf("writeMultipart() error = %v", err)
+		}
+		w.Header().Set("Content-Type", responseType)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		version  SOAPVersion
+		wantType string
+	}{
+		{name: "SOAP 1.1", version: SOAP11, wantType: "text/xml"},
+		{name: "SOAP 1.2", version: SOAP12, wantType: "application/soap+xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &NumberToWordsResponse{}
+			resp, err := New().R().
+				SetUrl(server.URL).
+				SetSOAPVersion(tt.version).
+				SetSOAPAction("urn:submit").
+				SetPayloadRequest(&NumberToWords{UbiNum: "cid:invoice@client"}).
+				SetPayloadResponse(result).
+				AddAttachment("<invoice@client>", "application/pdf", strings.NewReader("%PDF-1.4")).
+				AddAttachment("notes@client", "", strings.NewReader("notes")).
+				Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+
+			if mediaType != "multipart/related" || params["type"] != tt.wantType || params["start-info"] != "" {
+				t.Errorf("Content-Type = %v %v, want multipart/related of type %v", mediaType, params, tt.wantType)
+			}
+			if !strings.Contains(string(envelope), "cid:invoice@client") {
+				t.Errorf("root part = %s, want the envelope", envelope)
+			}
+			if len(received) != 2 {
+				t.Fatalf("received %d parts, want 2", len(received))
+			}
+			if p := received[0]; p.contentID != "invoice@client" || p.header.Get("Content-Type") != "application/pdf" || string(p.data) != "%PDF-1.4" {
+				t.Errorf("first part = %v %v %q, want the invoice", p.contentID, p.header, p.data)
+			}
+			if p := received[1]; p.contentID != "notes@client" || p.header.Get("Content-Type") != "application/octet-stream" || string(p.data) != "notes" {
+				t.Errorf("second part = %v %v %q, want the notes", p.contentID, p.header, p.data)
+			}
+
+			if result.NumberToWordsResult != "cid:receipt@service" {
+				t.Errorf("NumberToWordsResult = %v, want the reference", result.NumberToWordsResult)
+			}
+			attachments := resp.Attachments()
+			if len(attachments) != 1 || attachments[0].ContentID != "receipt@service" || attachments[0].ContentType != "text/plain" {
+				t.Fatalf("Attachments() = %v, want the receipt", attachments)
+			}
+			attachment := resp.Attachment(result.NumberToWordsResult)
+			if attachment == nil {
+				t.Fatalf("Attachment(%v) = nil", result.NumberToWordsResult)
+			}
+			if got, _ := ioutil.ReadAll(attachment.Content); string(got) != "received" {
+				t.Errorf("Attachment() content = %q, want received", got)
+			}
+			if resp.Attachment("unknown") != nil {
+				t.Errorf("Attachment(unknown) != nil")
+			}
+		})
+	}
+}
+
+func TestRequest_AddAttachment(t *testing.T) {
+	content := strings.NewReader("data")
+	r := New().R().AddAttachment("a@b", "text/plain", content)
+	want := []*Attachment{{ContentID: "a@b", ContentType: "text/plain", Content: content}}
+	if !reflect.DeepEqual(r.Attachments, want) {
+		t.Errorf("AddAttachment() = %v, want %v", r.Attachments, want)
+	}
+}
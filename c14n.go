@@ -0,0 +1,125 @@
+package soap
+
+import (
+	"bytes"
+	"sort"
+)
+
+// ExcC14N is the Exclusive XML Canonicalization 1.0 algorithm, without comments.
+const ExcC14N = "http://www.w3.org/2001/10/xml-exc-c14n#"
+
+// canonicalize returns the exclusive canonical form without comments of the
+// element subtree. The namespaces of the inclusive prefix list, "#default"
+// for the default namespace, are rendered as in inclusive canonicalization.
+func canonicalize(e *xmlElement, inclusive []string) []byte {
+	c := &canonicalizer{inclusive: map[string]bool{}}
+	for _, prefix := range inclusive {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		c.inclusive[prefix] = true
+	}
+	c.element(e, map[string]string{})
+	return c.buf.Bytes()
+}
+
+type canonicalizer struct {
+	buf       bytes.Buffer
+	inclusive map[string]bool
+}
+
+type canonicalAttr struct {
+	namespace string
+	name      string
+	local     string
+	value     string
+}
+
+// element writes the element, rendered holds the namespace declarations
+// already output by its ancestors.
+func (c *canonicalizer) element(e *xmlElement, rendered map[string]string) {
+	// The namespaces visibly utilized by the element and its attributes.
+	visible := map[string]bool{e.prefix: true}
+	var attrs []canonicalAttr
+	for _, a := range e.attrs {
+		if a.isNamespace() {
+			continue
+		}
+		if a.prefix != "" && a.prefix != "xml" {
+			visible[a.prefix] = true
+		}
+		attrs = append(attrs, canonicalAttr{
+			namespace: e.attrNamespace(a),
+			name:      qname(a.prefix, a.local),
+			local:     a.local,
+			value:     a.value,
+		})
+	}
+	for prefix := range c.inclusive {
+		if _, ok := e.lookupNamespace(prefix); ok {
+			visible[prefix] = true
+		}
+	}
+
+	var prefixes []string
+	scope := rendered
+	for prefix := range visible {
+		namespace, ok := e.lookupNamespace(prefix)
+		if !ok && prefix != "" {
+			continue
+		}
+		current, done := rendered[prefix]
+		if done && current == namespace {
+			continue
+		}
+		// An empty default namespace is only output to undeclare the
+		// default namespace of an output ancestor.
+		if prefix == "" && namespace == "" && !done {
+			continue
+		}
+		if len(prefixes) == 0 {
+			scope = make(map[string]string, len(rendered)+1)
+			for k, v := range rendered {
+				scope[k] = v
+			}
+		}
+		scope[prefix] = namespace
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].namespace != attrs[j].namespace {
+			return attrs[i].namespace < attrs[j].namespace
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	name := qname(e.prefix, e.local)
+	c.buf.WriteString("<" + name)
+	for _, prefix := range prefixes {
+		attr := "xmlns"
+		if prefix != "" {
+			attr += ":" + prefix
+		}
+		c.buf.WriteString(" " + attr + `="`)
+		escapeAttr(&c.buf, scope[prefix])
+		c.buf.WriteString(`"`)
+	}
+	for _, a := range attrs {
+		c.buf.WriteString(" " + a.name + `="`)
+		escapeAttr(&c.buf, a.value)
+		c.buf.WriteString(`"`)
+	}
+	c.buf.WriteString(">")
+	for _, child := range e.children {
+		switch n := child.(type) {
+		case *xmlElement:
+			c.element(n, scope)
+		case xmlText:
+			escapeText(&c.buf, string(n))
+		case xmlProcInst:
+			writeProcInst(&c.buf, n)
+		}
+	}
+	c.buf.WriteString("</" + name + ">")
+}
@@ -0,0 +1,69 @@
+package soap
+
+import (
+	"testing"
+)
+
+const c14nDocument = `<?xml version="1.0"?>
+<!-- comment -->
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:unused="urn:unused" xmlns="urn:default">
+  <soap:Body b="2" a="1" xmlns:z="urn:z" z:attr="&quot;x&#9;y&#10;">
+    <Op xmlns:m="urn:m" m:b="1" c="3"><m:item>1 &lt; 2 &amp; 3 &gt; 2</m:item><empty/><!-- c --><n xmlns=""><deep xmlns="urn:other"/></n></Op>
+  </soap:Body>
+</soap:Envelope>`
+
+func Test_canonicalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		document  string
+		path      []string
+		inclusive []string
+		want      string
+	}{
+		{
+			name:     "Document element",
+			document: c14nDocument,
+			want: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body xmlns:z="urn:z" a="1" b="2" z:attr="&quot;x&#x9;y&#xA;">
+    <Op xmlns="urn:default" xmlns:m="urn:m" c="3" m:b="1"><m:item>1 &lt; 2 &amp; 3 &gt; 2</m:item><empty></empty><n xmlns=""><deep xmlns="urn:other"></deep></n></Op>
+  </soap:Body>
+</soap:Envelope>`,
+		},
+		{
+			name:     "Subtree renders the namespaces of its ancestors",
+			document: c14nDocument,
+			path:     []string{"Body"},
+			want: `<soap:Body xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:z="urn:z" a="1" b="2" z:attr="&quot;x&#x9;y&#xA;">
+    <Op xmlns="urn:default" xmlns:m="urn:m" c="3" m:b="1"><m:item>1 &lt; 2 &amp; 3 &gt; 2</m:item><empty></empty><n xmlns=""><deep xmlns="urn:other"></deep></n></Op>
+  </soap:Body>`,
+		},
+		{
+			name:      "Inclusive namespace prefix list",
+			document:  c14nDocument,
+			path:      []string{"Body", "Op", "item"},
+			inclusive: []string{"soap", "#default"},
+			want:      `<m:item xmlns="urn:default" xmlns:m="urn:m" xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">1 &lt; 2 &amp; 3 &gt; 2</m:item>`,
+		},
+		{
+			// Example of the Exclusive XML Canonicalization recommendation.
+			name:     "Exclusive canonicalization example",
+			document: `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org"><n1:elem2 xmlns:n1="http://example.net" xml:lang="en"><n3:stuff xmlns:n3="ftp://example.org"/></n1:elem2></n0:local>`,
+			path:     []string{"elem2"},
+			want:     `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en"><n3:stuff xmlns:n3="ftp://example.org"></n3:stuff></n1:elem2>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseXML([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("parseXML() error = %v", err)
+			}
+			for _, local := range tt.path {
+				e = e.find(func(c *xmlElement) bool { return c.local == local })
+			}
+			if got := string(canonicalize(e, tt.inclusive)); got != tt.want {
+				t.Errorf("canonicalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package soap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailureRate is the failure rate opening a CircuitBreaker without FailureRate.
+	DefaultFailureRate = 0.5
+	// DefaultMinRequests is the number of calls of a CircuitBreaker without MinRequests
+	// before its failure rate is evaluated.
+	DefaultMinRequests = 10
+	// DefaultWindow is the period of a CircuitBreaker without Window over which the failure rate is computed.
+	DefaultWindow = time.Minute
+	// DefaultCooldown is the time a CircuitBreaker without Cooldown stays open.
+	DefaultCooldown = 30 * time.Second
+)
+
+// CircuitState is the state of a circuit of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets the calls through, counting their failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails the calls with ErrCircuitOpen without sending them.
+	CircuitOpen
+	// CircuitHalfOpen lets trial calls through after the cooldown, the
+	// circuit closing when they succeed and opening again when one fails.
+	CircuitHalfOpen
+)
+
+// String method returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitBreaker struct stops calling an endpoint that keeps failing. Each
+// endpoint URL, or each key returned by Key, has its own circuit. A circuit
+// opens when the rate of failed calls over the Window reaches FailureRate,
+// the calls then failing with ErrCircuitOpen for the Cooldown, after which
+// HalfOpenRequests trial calls decide whether it closes again.
+//
+// A CircuitBreaker must not be copied after its first use.
+type CircuitBreaker struct {
+	// Key returns the circuit of the request, its Url when nil, e.g. the Url
+	// and the SOAPAction for a circuit per operation.
+	Key func(r *Request) string
+	// FailureRate is the ratio of failed calls, from 0 to 1, opening the
+	// circuit, DefaultFailureRate when zero.
+	FailureRate float64
+	// MinRequests is the number of calls in the Window before the failure
+	// rate is evaluated, DefaultMinRequests when zero.
+	MinRequests int
+	Window      time.Duration
+	Cooldown    time.Duration
+	// HalfOpenRequests is the number of trial calls of a half-open circuit,
+	// 1 when zero.
+	HalfOpenRequests int
+	// Failure, when set, reports whether a call failed. By default the
+	// transport and read errors, the 5xx HTTP statuses and the `soap:Server`
+	// or `env:Receiver` faults are failures, a call canceled by its context
+	// is not counted.
+	Failure func(resp *Response, err error) bool
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of a key of the CircuitBreaker.
+type circuit struct {
+	state    CircuitState
+	start    time.Time
+	requests int
+	failures int
+	openedAt time.Time
+	trials   int
+	passed   int
+}
+
+// State method returns the state of the circuit of the key, the endpoint URL
+// unless the breaker has a Key function.
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && !now().Before(c.openedAt.Add(b.cooldown())) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// call sends the request through the circuit of its key.
+func (b *CircuitBreaker) call(r *Request, send func() (*Response, error)) (*Response, error) {
+	if b == nil {
+		return send()
+	}
+	key := r.Url
+	if b.Key != nil {
+		key = b.Key(r)
+	}
+	if !b.allow(key) {
+		return nil, newError(ErrCircuitOpen, errors.New(key))
+	}
+	resp, err := send()
+	b.record(key, resp, err)
+	return resp, err
+}
+
+// allow reports whether a call goes through the circuit of the key.
+func (b *CircuitBreaker) allow(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.circuits == nil {
+		b.circuits = map[string]*circuit{}
+	}
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	current := now()
+	switch c.state {
+	case CircuitOpen:
+		if current.Before(c.openedAt.Add(b.cooldown())) {
+			return false
+		}
+		c.state, c.trials, c.passed = CircuitHalfOpen, 0, 0
+		fallthrough
+	case CircuitHalfOpen:
+		if c.trials >= b.halfOpenRequests() {
+			return false
+		}
+		c.trials++
+	default:
+		if current.Sub(c.start) >= b.window() {
+			c.start, c.requests, c.failures = current, 0, 0
+		}
+	}
+	return true
+}
+
+// record counts the outcome of a call through the circuit of the key.
+func (b *CircuitBreaker) record(key string, resp *Response, err error) {
+	canceled := errors.Is(err, context.Canceled)
+	failed := !canceled && b.failed(resp, err)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[key]
+	switch c.state {
+	case CircuitHalfOpen:
+		switch {
+		case failed:
+			c.state, c.openedAt = CircuitOpen, now()
+		case canceled:
+			c.trials--
+		default:
+			if c.passed++; c.passed >= b.halfOpenRequests() {
+				*c = circuit{state: CircuitClosed, start: now()}
+			}
+		}
+	case CircuitClosed:
+		if canceled {
+			return
+		}
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if c.requests >= b.minRequests() && float64(c.failures) >= b.failureRate()*float64(c.requests) {
+			c.state, c.openedAt = CircuitOpen, now()
+		}
+	}
+}
+
+// failed reports whether the call failed.
+func (b *CircuitBreaker) failed(resp *Response, err error) bool {
+	if b.Failure != nil {
+		return b.Failure(resp, err)
+	}
+	var fault *Fault
+	switch {
+	case errors.As(err, &fault):
+		return fault.CodeLocal() == "Server" || fault.CodeLocal() == "Receiver"
+	case errors.Is(err, ErrTransport), errors.Is(err, ErrRead):
+		return true
+	case resp != nil:
+		return resp.StatusCode() >= http.StatusInternalServerError
+	}
+	return false
+}
+
+func (b *CircuitBreaker) failureRate() float64 {
+	if b.FailureRate > 0 {
+		return b.FailureRate
+	}
+	return DefaultFailureRate
+}
+
+func (b *CircuitBreaker) minRequests() int {
+	if b.MinRequests > 0 {
+		return b.MinRequests
+	}
+	return DefaultMinRequests
+}
+
+func (b *CircuitBreaker) window() time.Duration {
+	if b.Window > 0 {
+		return b.Window
+	}
+	return DefaultWindow
+}
+
+func (b *CircuitBreaker) cooldown() time.Duration {
+	if b.Cooldown > 0 {
+		return b.Cooldown
+	}
+	return DefaultCooldown
+}
+
+func (b *CircuitBreaker) halfOpenRequests() int {
+	if b.HalfOpenRequests > 0 {
+		return b.HalfOpenRequests
+	}
+	return 1
+}
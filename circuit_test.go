@@ -0,0 +1,121 @@
+package soap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequest_Call_CircuitBreaker(t *testing.T) {
+	var failing, sent int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	current := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	breaker := &CircuitBreaker{MinRequests: 4, FailureRate: 0.5, Cooldown: time.Minute}
+	client := New().SetCircuitBreaker(breaker)
+	call := func(action string) error {
+		_, err := client.R().
+			SetUrl(server.URL).
+			SetSOAPAction(action).
+			SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+			Call()
+		return err
+	}
+	steps := []struct {
+		name      string
+		failing   int32
+		advance   time.Duration
+		wantErr   error
+		wantState CircuitState
+		wantSent  int32
+	}{
+		{name: "Success", wantState: CircuitClosed, wantSent: 1},
+		{name: "Success", wantState: CircuitClosed, wantSent: 1},
+		{name: "Failure", failing: 1, wantErr: ErrStatus, wantState: CircuitClosed, wantSent: 1},
+		{name: "Failure at the rate", failing: 1, wantErr: ErrStatus, wantState: CircuitOpen, wantSent: 1},
+		{name: "Open circuit", wantErr: ErrCircuitOpen, wantState: CircuitOpen},
+		{name: "Open circuit before the cooldown", advance: 59 * time.Second, wantErr: ErrCircuitOpen, wantState: CircuitOpen},
+		{name: "Failed trial", advance: time.Second, failing: 1, wantErr: ErrStatus, wantState: CircuitOpen, wantSent: 1},
+		{name: "Open circuit after the trial", wantErr: ErrCircuitOpen, wantState: CircuitOpen},
+		{name: "Successful trial", advance: time.Minute, wantState: CircuitClosed, wantSent: 1},
+		{name: "Failure under the minimum requests", failing: 1, wantErr: ErrStatus, wantState: CircuitClosed, wantSent: 1},
+	}
+	for _, step := range steps {
+		current = current.Add(step.advance)
+		atomic.StoreInt32(&failing, step.failing)
+		atomic.StoreInt32(&sent, 0)
+		err := call("")
+		if step.wantErr == nil && err != nil || !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: Call() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if got := breaker.State(server.URL); got != step.wantState {
+			t.Errorf("%s: State() = %v, want %v", step.name, got, step.wantState)
+		}
+		if got := atomic.LoadInt32(&sent); got != step.wantSent {
+			t.Errorf("%s: sent %v requests, want %v", step.name, got, step.wantSent)
+		}
+	}
+
+	t.Run("Circuit per operation", func(t *testing.T) {
+		breaker := &CircuitBreaker{
+			Key:         func(r *Request) string { return r.SOAPAction },
+			MinRequests: 1,
+		}
+		client = New().SetCircuitBreaker(breaker)
+		atomic.StoreInt32(&failing, 1)
+		if err := call("urn:get"); !errors.Is(err, ErrStatus) {
+			t.Fatalf("Call() error = %v, want ErrStatus", err)
+		}
+		atomic.StoreInt32(&failing, 0)
+		if err := call("urn:get"); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Call(urn:get) error = %v, want ErrCircuitOpen", err)
+		}
+		if err := call("urn:list"); err != nil {
+			t.Errorf("Call(urn:list) error = %v", err)
+		}
+	})
+
+	t.Run("Canceled call", func(t *testing.T) {
+		breaker := &CircuitBreaker{MinRequests: 1}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := New().SetCircuitBreaker(breaker).R().
+			SetUrl(server.URL).
+			SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+			CallContext(ctx)
+		if !errors.Is(err, ErrTransport) || breaker.State(server.URL) != CircuitClosed {
+			t.Errorf("Call() error = %v, State() = %v, want ErrTransport and a closed circuit", err, breaker.State(server.URL))
+		}
+	})
+}
+
+func TestCircuitState_String(t *testing.T) {
+	tests := []struct {
+		state CircuitState
+		want  string
+	}{
+		{CircuitClosed, "closed"},
+		{CircuitOpen, "open"},
+		{CircuitHalfOpen, "half-open"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("String() = %v, want %v", got, tt.want)
+		}
+	}
+}
@@ -3,12 +3,33 @@ package soap
 import (
 	"net"
 	"net/http"
+	"reflect"
 	"runtime"
+	"strings"
 	"time"
 )
 
 type Client struct {
-	httpClient *http.Client
+	httpClient   *http.Client
+	baseURL      string
+	header       http.Header
+	payloadFault reflect.Type
+	soapVersion  SOAPVersion
+	wsSecurity   *WSSecurity
+	addressing   *Addressing
+	mtom         bool
+	streaming    bool
+	maxResponse  int64
+	retryPolicy  *RetryPolicy
+	breaker      *CircuitBreaker
+	beforeSend   []BeforeSendHook
+	afterReceive []AfterReceiveHook
+	onError      []ErrorHook
+	middlewares  []Middleware
+	metrics      Metrics
+	logger       Logger
+	redacted     []string
+	logBodySize  int
 }
 
 func NewClient(hc *http.Client) *Client {
@@ -20,12 +41,21 @@ func NewClient(hc *http.Client) *Client {
 	}
 }
 
-// R method creates a new request instance
+// R method creates a new request instance, with the base URL, the headers and
+// a new payload fault of the client.
 func (c *Client) R() *Request {
-	return &Request{
+	r := &Request{
 		client: c,
+		Url:    c.baseURL,
 		Header: http.Header{},
 	}
+	for header, values := range c.header {
+		r.Header[header] = append([]string(nil), values...)
+	}
+	if c.payloadFault != nil {
+		r.PayloadFault = reflect.New(c.payloadFault).Interface()
+	}
+	return r
 }
 
 // NewRequest is an alias for method `R()`. Creates a new request instance
@@ -33,6 +63,53 @@ func (c *Client) NewRequest() *Request {
 	return c.R()
 }
 
+// SetBaseURL method sets the endpoint URL of the requests raised from client.
+// A request URL set without scheme and host, a path or a query, is resolved
+// against it when the request is called.
+//		client.SetBaseURL("https://www.dataaccess.com/webservicesserver/")
+//		client.R().
+//			SetUrl("NumberConversion.wso")
+func (c *Client) SetBaseURL(url string) *Client {
+	c.baseURL = url
+	return c
+}
+
+// SetHeader method sets a header field and its value sent by the requests
+// raised from client, which can override it.
+//		client.SetHeader("X-Partner-Id", "42")
+func (c *Client) SetHeader(header, value string) *Client {
+	if c.header == nil {
+		c.header = http.Header{}
+	}
+	c.header.Set(header, value)
+	return c
+}
+
+// SetHeaders method sets several header fields and their values sent by the
+// requests raised from client, which can override them.
+//		client.SetHeaders(map[string]string{
+//			"X-Partner-Id": "42",
+//			"Accept":       "text/xml",
+//		})
+func (c *Client) SetHeaders(headers map[string]string) *Client {
+	for header, value := range headers {
+		c.SetHeader(header, value)
+	}
+	return c
+}
+
+// SetPayloadFault method sets the type of the SOAP Fault decoded by the
+// requests raised from client, each request having its own value of it, see
+// Request.SetPayloadFault.
+//		client.SetPayloadFault(&MyServiceFault{})
+func (c *Client) SetPayloadFault(payloadFault interface{}) *Client {
+	c.payloadFault = nil
+	if payloadFault != nil {
+		c.payloadFault = reflect.TypeOf(getPointer(payloadFault)).Elem()
+	}
+	return c
+}
+
 // SetTimeout method sets timeout for request raised from client.
 //		client.SetTimeout(time.Duration(1 * time.Second))
 func (c *Client) SetTimeOut(timeout time.Duration) *Client {
@@ -40,6 +117,170 @@ func (c *Client) SetTimeOut(timeout time.Duration) *Client {
 	return c
 }
 
+// SetSOAPVersion method sets the SOAP version of the requests raised from client.
+// Requests default to SOAP 1.1.
+//		client.SetSOAPVersion(soap.SOAP12)
+func (c *Client) SetSOAPVersion(version SOAPVersion) *Client {
+	c.soapVersion = version
+	return c
+}
+
+// SetWSSecurity method sets the WS-Security header added to the requests
+// raised from client.
+//		client.SetWSSecurity(&soap.WSSecurity{
+//			UsernameToken: &soap.UsernameToken{Username: "user", Password: "secret"},
+//		})
+func (c *Client) SetWSSecurity(security *WSSecurity) *Client {
+	c.wsSecurity = security
+	return c
+}
+
+// SetAddressing method sets the WS-Addressing headers added to the requests
+// raised from client.
+//		client.SetAddressing(&soap.Addressing{MustUnderstand: true})
+func (c *Client) SetAddressing(addressing *Addressing) *Client {
+	c.addressing = addressing
+	return c
+}
+
+// SetMTOM method sends the requests raised from client as MTOM messages,
+// their binary fields being sent as XOP parts.
+//		client.SetMTOM(true)
+func (c *Client) SetMTOM(enabled bool) *Client {
+	c.mtom = enabled
+	return c
+}
+
+// SetStreaming method streams the envelopes of the requests raised from
+// client, see Request.SetStreaming.
+//		client.SetStreaming(true)
+func (c *Client) SetStreaming(enabled bool) *Client {
+	c.streaming = enabled
+	return c
+}
+
+// SetMaxResponseSize method sets the maximum size in bytes of the response
+// bodies of the requests raised from client, zero means no limit.
+//		client.SetMaxResponseSize(100 << 20)
+func (c *Client) SetMaxResponseSize(size int64) *Client {
+	c.maxResponse = size
+	return c
+}
+
+// SetRetryPolicy method sets the retry policy of the idempotent requests
+// raised from client, see RetryPolicy.
+//		client.SetRetryPolicy(&soap.RetryPolicy{
+//			MaxAttempts: 4,
+//			Jitter:      0.5,
+//		})
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) *Client {
+	c.retryPolicy = policy
+	return c
+}
+
+// SetCircuitBreaker method sets the circuit breaker of the endpoints called
+// by the requests raised from client, see CircuitBreaker.
+//		client.SetCircuitBreaker(&soap.CircuitBreaker{
+//			FailureRate: 0.5,
+//			Cooldown:    30 * time.Second,
+//		})
+func (c *Client) SetCircuitBreaker(breaker *CircuitBreaker) *Client {
+	c.breaker = breaker
+	return c
+}
+
+// OnBeforeSend method appends a hook called before sending the requests
+// raised from client, ahead of the request hooks, see BeforeSendHook.
+//		client.OnBeforeSend(func(r *soap.Request, req *http.Request, envelope []byte) ([]byte, error) {
+//			req.Header.Set("X-Signature", sign(envelope))
+//			return envelope, nil
+//		})
+func (c *Client) OnBeforeSend(hook BeforeSendHook) *Client {
+	c.beforeSend = append(c.beforeSend, hook)
+	return c
+}
+
+// OnAfterReceive method appends a hook called with the responses of the
+// requests raised from client before they are decoded, ahead of the request
+// hooks, see AfterReceiveHook.
+//		client.OnAfterReceive(func(resp *soap.Response, body []byte) ([]byte, error) {
+//			audit.Record(resp.StatusCode(), body)
+//			return body, nil
+//		})
+func (c *Client) OnAfterReceive(hook AfterReceiveHook) *Client {
+	c.afterReceive = append(c.afterReceive, hook)
+	return c
+}
+
+// OnError method appends a hook called when a request raised from client
+// fails, ahead of the request hooks, see ErrorHook.
+//		client.OnError(func(r *soap.Request, resp *soap.Response, err error) error {
+//			log.Printf("%s: %v", r.SOAPAction, err)
+//			return err
+//		})
+func (c *Client) OnError(hook ErrorHook) *Client {
+	c.onError = append(c.onError, hook)
+	return c
+}
+
+// Use method appends a middleware wrapping the calls of the requests raised
+// from client, the first one being the outermost, see Middleware.
+//		client.Use(otelsoap.Middleware())
+func (c *Client) Use(middleware Middleware) *Client {
+	c.middlewares = append(c.middlewares, middleware)
+	return c
+}
+
+// SetMetrics method sets the metrics recording each attempt of the requests
+// raised from client, see Metrics.
+//		collector := promsoap.NewCollector(promsoap.CollectorOpts{})
+//		prometheus.MustRegister(collector)
+//		client.SetMetrics(collector)
+func (c *Client) SetMetrics(metrics Metrics) *Client {
+	c.metrics = metrics
+	return c
+}
+
+// SetLogger method sets the structured logger of the requests raised from
+// client, such as a *slog.Logger. The envelopes sent and received are logged
+// at debug level, with their redacted elements masked, and the failed calls
+// at warning level.
+//		client.SetLogger(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+func (c *Client) SetLogger(logger Logger) *Client {
+	c.logger = logger
+	return c
+}
+
+// SetLogRedaction method sets the elements whose content is masked in the
+// logged envelopes, by local name or by prefixed name, replacing
+// DefaultRedactedElements.
+//		client.SetLogRedaction("Password", "CardNumber", "wsse:Nonce")
+func (c *Client) SetLogRedaction(elements ...string) *Client {
+	c.redacted = append([]string{}, elements...)
+	return c
+}
+
+// SetLogMaxBodySize method sets the number of bytes of the logged envelopes
+// kept, DefaultLogBodySize when zero, a negative size keeping them whole.
+//		client.SetLogMaxBodySize(64 << 10)
+func (c *Client) SetLogMaxBodySize(size int) *Client {
+	c.logBodySize = size
+	return c
+}
+
+// resolveURL returns the request URL resolved against the base URL of the
+// client when it has no scheme.
+func (c *Client) resolveURL(url string) string {
+	if c.baseURL == "" || url == c.baseURL || strings.Contains(url, "://") {
+		return url
+	}
+	base := strings.TrimRight(c.baseURL, "/")
+	if url == "" || strings.HasPrefix(url, "?") {
+		return base + url
+	}
+	return base + "/" + strings.TrimLeft(url, "/")
+}
+
 func createTransport(httpTransport *http.Transport) *http.Transport {
 
 	if httpTransport != nil {
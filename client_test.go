@@ -1,10 +1,13 @@
 package soap
 
 import (
+	"errors"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -209,3 +212,163 @@ func Test_createTransport(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_SetSOAPVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version SOAPVersion
+		want    SOAPVersion
+	}{
+		{name: "Set SOAP 1.1", version: SOAP11, want: SOAP11},
+		{name: "Set SOAP 1.2", version: SOAP12, want: SOAP12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New().SetSOAPVersion(tt.version); got.soapVersion != tt.want {
+				t.Errorf("SetSOAPVersion() = %v, want %v", got.soapVersion, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_SetWSSecurity(t *testing.T) {
+	security := &WSSecurity{UsernameToken: &UsernameToken{Username: "user", Password: "secret"}}
+	if got := New().SetWSSecurity(security); got.wsSecurity != security {
+		t.Errorf("SetWSSecurity() = %v, want %v", got.wsSecurity, security)
+	}
+}
+
+func TestClient_SetAddressing(t *testing.T) {
+	addressing := &Addressing{MustUnderstand: true}
+	if got := New().SetAddressing(addressing); got.addressing != addressing {
+		t.Errorf("SetAddressing() = %v, want %v", got.addressing, addressing)
+	}
+}
+
+func TestClient_SetMTOM(t *testing.T) {
+	if got := New().SetMTOM(true); !got.mtom {
+		t.Errorf("SetMTOM() = %v, want true", got.mtom)
+	}
+}
+
+func TestClient_SetStreaming(t *testing.T) {
+	c := New().SetStreaming(true).SetMaxResponseSize(1 << 20)
+	if !c.streaming || c.maxResponse != 1<<20 {
+		t.Errorf("SetStreaming() = %v, SetMaxResponseSize() = %v, want true, %v", c.streaming, c.maxResponse, 1<<20)
+	}
+}
+
+func TestClient_resolveURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		url     string
+		want    string
+	}{
+		{name: "No base URL", url: "http://host/a", want: "http://host/a"},
+		{name: "Base URL", baseURL: "http://host/ws", want: "http://host/ws"},
+		{name: "Inherited base URL", baseURL: "http://host/ws/", url: "http://host/ws/", want: "http://host/ws/"},
+		{name: "Path", baseURL: "http://host/ws/", url: "NumberConversion.wso", want: "http://host/ws/NumberConversion.wso"},
+		{name: "Absolute path", baseURL: "http://host/ws", url: "/NumberConversion.wso", want: "http://host/ws/NumberConversion.wso"},
+		{name: "Query", baseURL: "http://host/ws/NumberConversion.wso", url: "?op=NumberToWords", want: "http://host/ws/NumberConversion.wso?op=NumberToWords"},
+		{name: "Absolute URL", baseURL: "http://host/ws", url: "https://other/ws", want: "https://other/ws"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New().SetBaseURL(tt.baseURL).resolveURL(tt.url); got != tt.want {
+				t.Errorf("resolveURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_SetHeaders(t *testing.T) {
+	c := New().
+		SetHeader("X-Partner-Id", "42").
+		SetHeaders(map[string]string{"Accept": "text/xml", "X-Trace": "on"})
+	r := c.R().SetHeader("X-Trace", "off")
+	want := http.Header{"X-Partner-Id": {"42"}, "Accept": {"text/xml"}, "X-Trace": {"off"}}
+	if !reflect.DeepEqual(r.Header, want) {
+		t.Errorf("R() Header = %v, want %v", r.Header, want)
+	}
+	if got := c.R().Header.Get("X-Trace"); got != "on" {
+		t.Errorf("R() Header X-Trace = %v, want on", got)
+	}
+}
+
+func TestClient_SetPayloadFault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`<html><body>Service Unavailable</body></html>`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		fault interface{}
+		want  interface{}
+	}{
+		{name: "Pointer", fault: &DummyFault{Soap: "ignored"}, want: &DummyFault{}},
+		{name: "Value", fault: DummyFault{}, want: &DummyFault{}},
+		{name: "Nil", fault: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New().SetPayloadFault(tt.fault)
+			first, second := c.R().PayloadFault, c.R().PayloadFault
+			if !reflect.DeepEqual(first, tt.want) {
+				t.Errorf("R() PayloadFault = %v, want %v", first, tt.want)
+			}
+			if first != nil && first == second {
+				t.Errorf("R() PayloadFault is shared between requests")
+			}
+
+			// A response without SOAP Fault is a status error, whatever the fault type.
+			_, err := c.R().
+				SetUrl(server.URL).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				Call()
+			if !errors.Is(err, ErrStatus) {
+				t.Errorf("Call() error = %v, want ErrStatus", err)
+			}
+		})
+	}
+}
+
+func TestClient_defaults(t *testing.T) {
+	fault := `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><soap:Fault><soap:Code><soap:Value>soap:Sender</soap:Value></soap:Code><soap:Reason><soap:Text xml:lang="en">invalid</soap:Text></soap:Reason></soap:Fault></soap:Body></soap:Envelope>`
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fault))
+	}))
+	defer server.Close()
+
+	type Reason struct {
+		Text string `xml:"Reason>Text"`
+	}
+	resp, err := New().
+		SetBaseURL(server.URL+"/ws/").
+		SetHeader("X-Partner-Id", "42").
+		SetSOAPVersion(SOAP12).
+		SetPayloadFault(&Reason{}).
+		R().
+		SetUrl("NumberConversion.wso?op=NumberToWords").
+		SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+		Call()
+
+	var soapFault *Fault
+	if !errors.As(err, &soapFault) {
+		t.Fatalf("Call() error = %v, want a *Fault", err)
+	}
+	if got.URL.RequestURI() != "/ws/NumberConversion.wso?op=NumberToWords" {
+		t.Errorf("request URI = %v, want /ws/NumberConversion.wso?op=NumberToWords", got.URL.RequestURI())
+	}
+	if got.Header.Get("X-Partner-Id") != "42" || !strings.HasPrefix(got.Header.Get("Content-Type"), "application/soap+xml") {
+		t.Errorf("request headers = %v, want the client headers and SOAP 1.2", got.Header)
+	}
+	if reason := resp.PayloadResultError().(*Reason); reason.Text != "invalid" {
+		t.Errorf("PayloadResultError() = %v, want invalid", reason.Text)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mencosk/soap/wsdl"
+)
+
+// operation is the payload types of a bound operation.
+type operation struct {
+	name    string
+	doc     string
+	action  string
+	request string
+	output  string
+	fault   string
+}
+
+// clients writes one client per SOAP port, with a method per operation.
+func (g *generator) clients() {
+	wrappers := map[string]*operation{}
+	for _, service := range g.defs.Services {
+		for _, port := range service.Ports {
+			binding := g.defs.Binding(port.Binding)
+			if binding == nil || binding.Version() == "" {
+				continue
+			}
+			portType := g.defs.PortType(binding.Type)
+			if portType == nil {
+				g.warnf("port type %s of binding %s not found", binding.Type, binding.Name)
+				continue
+			}
+
+			var ops []*operation
+			for _, bop := range binding.Operations {
+				key := binding.Namespace + " " + binding.Name + " " + bop.Name
+				op, ok := wrappers[key]
+				if !ok {
+					op = g.operation(binding, bop, portType.Operation(bop.Name))
+					wrappers[key] = op
+				}
+				if op != nil {
+					ops = append(ops, op)
+				}
+			}
+			g.client(service, port, binding, ops)
+		}
+	}
+}
+
+// operation resolves the request, response and fault types of a binding
+// operation, writing the wrappers of the rpc style operations.
+func (g *generator) operation(binding *wsdl.Binding, bop *wsdl.BindingOperation, op *wsdl.Operation) *operation {
+	if op == nil {
+		g.warnf("operation %s of binding %s not found in its port type", bop.Name, binding.Name)
+		return nil
+	}
+	if op.Input == nil {
+		g.warnf("operation %s skipped, notification operations are not supported", op.Name)
+		return nil
+	}
+	for _, m := range []*wsdl.BindingMessage{bop.Input, bop.Output} {
+		if m != nil && m.Body() != nil && m.Body().Use == "encoded" {
+			g.warnf("operation %s skipped, encoded use is not supported", op.Name)
+			return nil
+		}
+	}
+
+	o := &operation{name: identifier(op.Name), doc: op.Doc, action: bop.Action()}
+	rpc := bop.Style(binding) == "rpc"
+	var ok bool
+	if o.request, ok = g.payload(binding, op.Name, op.Input, bop.Input, rpc, false); !ok {
+		return nil
+	}
+	if op.Output != nil {
+		if o.output, ok = g.payload(binding, op.Name, op.Output, bop.Output, rpc, true); !ok {
+			return nil
+		}
+	}
+	if len(op.Faults) == 1 {
+		if m := g.defs.Message(op.Faults[0].Message); m != nil && len(m.Parts) == 1 {
+			o.fault = g.elements[m.Parts[0].Element]
+		}
+	}
+	return o
+}
+
+// payload returns the Go type of the body of an operation message.
+func (g *generator) payload(binding *wsdl.Binding, name string, io *wsdl.IOMessage, bm *wsdl.BindingMessage, rpc, response bool) (string, bool) {
+	m := g.defs.Message(io.Message)
+	if m == nil {
+		g.warnf("message %s of operation %s not found", io.Message, name)
+		return "", false
+	}
+	parts := bodyParts(m, bm)
+
+	if rpc {
+		namespace := binding.Namespace
+		if bm != nil && bm.Body() != nil && bm.Body().Namespace != "" {
+			namespace = bm.Body().Namespace
+		}
+		// The rpc wrapper of the response is named after the operation with
+		// a Response suffix.
+		kind, element := "Request", name
+		if response {
+			kind, element = "Response", name+"Response"
+		}
+		wrapper := g.name(identifier(name)+kind, "Type")
+		g.imports["encoding/xml"] = true
+		g.printf("\n// %s is the %s message of the %s operation.\ntype %s struct {\n", wrapper, strings.ToLower(kind), name, wrapper)
+		g.printf("\tXMLName xml.Name `xml:\"%s %s\"`\n", namespace, element)
+		for _, p := range parts {
+			typ, isStruct, tag := "", false, p.Name
+			if !p.Element.IsZero() {
+				typ, isStruct, tag = g.elements[p.Element], true, p.Element.Space+" "+p.Element.Local
+			} else {
+				typ, isStruct = g.goType(p.Type)
+			}
+			if isStruct {
+				typ = "*" + typ
+			}
+			g.printf("\t%s %s `xml:\"%s\"`\n", identifier(p.Name), typ, tag)
+		}
+		g.printf("}\n")
+		g.prefixed(wrapper, namespace, element)
+		return wrapper, true
+	}
+
+	if len(parts) != 1 || parts[0].Element.IsZero() {
+		g.warnf("operation %s skipped, document style messages must have a single element part", name)
+		return "", false
+	}
+	typ, ok := g.elements[parts[0].Element]
+	if !ok {
+		g.warnf("element %s of operation %s not found", parts[0].Element, name)
+		return "", false
+	}
+	return typ, true
+}
+
+// bodyParts returns the parts of a message bound to the SOAP body.
+func bodyParts(m *wsdl.Message, bm *wsdl.BindingMessage) []*wsdl.Part {
+	var body map[string]bool
+	headers := map[string]bool{}
+	if bm != nil {
+		if b := bm.Body(); b != nil && strings.TrimSpace(b.Parts) != "" {
+			body = map[string]bool{}
+			for _, name := range strings.Fields(b.Parts) {
+				body[name] = true
+			}
+		}
+		for _, h := range bm.Headers() {
+			if h.Message.Local == m.Name && h.Message.Space == m.Namespace {
+				headers[h.Part] = true
+			}
+		}
+	}
+	var parts []*wsdl.Part
+	for _, p := range m.Parts {
+		if (body == nil || body[p.Name]) && !headers[p.Name] {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// client writes the client of a port and its operation methods.
+func (g *generator) client(service *wsdl.Service, port *wsdl.Port, binding *wsdl.Binding, ops []*operation) {
+	g.imports["github.com/mencosk/soap"] = true
+	name := g.name(identifier(port.Name), "Client")
+	version := "soap.SOAP11"
+	if binding.Version() == "1.2" {
+		version = "soap.SOAP12"
+	}
+
+	g.printf(`
+// %[1]s is the client of the %[2]s port of the %[3]s
+// service.
+type %[1]s struct {
+	client *soap.Client
+	url    string
+}
+
+// New%[1]s returns a client of the port calling the url, the address of
+// the port in the WSDL if empty. A nil client uses soap.New().
+func New%[1]s(client *soap.Client, url string) *%[1]s {
+	if client == nil {
+		client = soap.New()
+	}
+	if url == "" {
+		url = %[4]q
+	}
+	return &%[1]s{client: client, url: url}
+}
+`, name, port.Name, service.Name, port.Address())
+
+	for _, op := range ops {
+		g.imports["context"] = true
+		g.printf("\n// %s calls the %s operation.\n", op.name, op.name)
+		if doc := comment(op.doc, ""); doc != "" {
+			g.printf("//\n%s", doc)
+		}
+		if op.output == "" {
+			g.printf("func (c *%s) %s(ctx context.Context, request *%s) error {\n", name, op.name, op.request)
+			g.printf("\t_, err := c.client.R().\n")
+		} else {
+			g.printf("func (c *%s) %s(ctx context.Context, request *%s) (*%s, error) {\n", name, op.name, op.request, op.output)
+			g.printf("\tresponse := &%s{}\n", op.output)
+			g.printf("\t_, err := c.client.R().\n")
+		}
+		g.printf("\t\tSetUrl(c.url).\n")
+		g.printf("\t\tSetSOAPVersion(%s).\n", version)
+		if op.action != "" {
+			g.printf("\t\tSetSOAPAction(%q).\n", op.action)
+		}
+		g.printf("\t\tSetPayloadRequest(request).\n")
+		if op.output != "" {
+			g.printf("\t\tSetPayloadResponse(response).\n")
+		}
+		if op.fault != "" {
+			g.printf("\t\tSetFaultDetail(&%s{}).\n", op.fault)
+		}
+		g.printf("\t\tCallContext(ctx)\n")
+		if op.output == "" {
+			g.printf("\treturn err\n}\n")
+			continue
+		}
+		g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn response, nil\n}\n")
+	}
+}
@@ -0,0 +1,605 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mencosk/soap/wsdl"
+)
+
+// xsdTypes maps the XML Schema built-in types to Go types. Date and time
+// types are kept as strings since their lexical forms do not all parse as
+// time.Time.
+var xsdTypes = map[string]string{
+	"anySimpleType":      "string",
+	"anyURI":             "string",
+	"boolean":            "bool",
+	"byte":               "int8",
+	"date":               "string",
+	"dateTime":           "string",
+	"decimal":            "float64",
+	"double":             "float64",
+	"duration":           "string",
+	"ENTITIES":           "string",
+	"ENTITY":             "string",
+	"float":              "float32",
+	"gDay":               "string",
+	"gMonth":             "string",
+	"gMonthDay":          "string",
+	"gYear":              "string",
+	"gYearMonth":         "string",
+	"hexBinary":          "string",
+	"ID":                 "string",
+	"IDREF":              "string",
+	"IDREFS":             "string",
+	"int":                "int32",
+	"integer":            "int64",
+	"language":           "string",
+	"long":               "int64",
+	"Name":               "string",
+	"NCName":             "string",
+	"negativeInteger":    "int64",
+	"NMTOKEN":            "string",
+	"NMTOKENS":           "string",
+	"nonNegativeInteger": "uint64",
+	"nonPositiveInteger": "int64",
+	"normalizedString":   "string",
+	"NOTATION":           "string",
+	"positiveInteger":    "uint64",
+	"QName":              "string",
+	"short":              "int16",
+	"string":             "string",
+	"time":               "string",
+	"token":              "string",
+	"unsignedByte":       "uint8",
+	"unsignedInt":        "uint32",
+	"unsignedLong":       "uint64",
+	"unsignedShort":      "uint16",
+}
+
+// Generated helper types, emitted only when the schemas use them.
+const (
+	base64Type = "Base64Binary"
+	anyType    = "AnyType"
+)
+
+var helpers = map[string]string{
+	base64Type: `
+// Base64Binary is the xsd:base64Binary type, encoded in base64.
+type Base64Binary []byte
+
+// MarshalText method encodes the bytes in base64.
+func (b Base64Binary) MarshalText() ([]byte, error) {
+	return []byte(base64.StdEncoding.EncodeToString(b)), nil
+}
+
+// UnmarshalText method decodes the base64 text.
+func (b *Base64Binary) UnmarshalText(text []byte) error {
+	v, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(text)))
+	*b = v
+	return err
+}
+`,
+	anyType: `
+// AnyType holds the raw XML of an xsd:anyType or xsd:any element.
+type AnyType struct {
+	XMLName xml.Name
+	Content string ` + "`xml:\",innerxml\"`" + `
+}
+`,
+}
+
+// generator writes the Go source for the types and the clients of a WSDL.
+type generator struct {
+	defs     *wsdl.Definitions
+	buf      bytes.Buffer
+	names    map[string]bool
+	elements map[wsdl.QName]string
+	complex  map[wsdl.QName]string
+	simple   map[wsdl.QName]string
+	helpers  map[string]bool
+	imports  map[string]bool
+	pending  []func()
+	warnings []string
+}
+
+// Generate method returns the formatted Go source of the package generated
+// from the definitions, along with warnings for the constructs it skipped.
+func Generate(defs *wsdl.Definitions, pkg string) ([]byte, []string, error) {
+	g := &generator{
+		defs:     defs,
+		names:    map[string]bool{},
+		elements: map[wsdl.QName]string{},
+		complex:  map[wsdl.QName]string{},
+		simple:   map[wsdl.QName]string{},
+		helpers:  map[string]bool{},
+		imports:  map[string]bool{},
+	}
+	g.register()
+	g.types()
+	g.clients()
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by soapgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	for name := range g.helpers {
+		switch name {
+		case base64Type:
+			g.imports["encoding/base64"] = true
+			g.imports["strings"] = true
+		case anyType:
+			g.imports["encoding/xml"] = true
+		}
+	}
+	if len(g.imports) > 0 {
+		// The standard library imports are grouped before the others.
+		var std, other []string
+		for path := range g.imports {
+			if strings.Contains(path, ".") {
+				other = append(other, path)
+			} else {
+				std = append(std, path)
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(other)
+		src.WriteString("import (\n")
+		for _, path := range std {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		if len(std) > 0 && len(other) > 0 {
+			src.WriteString("\n")
+		}
+		for _, path := range other {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		src.WriteString(")\n")
+	}
+	src.Write(g.buf.Bytes())
+	for _, name := range []string{base64Type, anyType} {
+		if g.helpers[name] {
+			src.WriteString(helpers[name])
+		}
+	}
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return src.Bytes(), g.warnings, fmt.Errorf("format generated source: %v", err)
+	}
+	return out, g.warnings, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) warnf(format string, args ...interface{}) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+// register assigns the Go names of the top level elements, then of the named
+// types, which get a Type suffix when an element already uses their name.
+func (g *generator) register() {
+	for _, s := range g.defs.Types.Schemas {
+		for _, e := range s.Elements {
+			g.elements[wsdl.QName{Space: s.TargetNamespace, Local: e.Name}] = g.name(identifier(e.Name), "Element")
+		}
+	}
+	for _, s := range g.defs.Types.Schemas {
+		for _, t := range s.ComplexTypes {
+			g.complex[wsdl.QName{Space: s.TargetNamespace, Local: t.Name}] = g.name(identifier(t.Name), "Type")
+		}
+		for _, t := range s.SimpleTypes {
+			g.simple[wsdl.QName{Space: s.TargetNamespace, Local: t.Name}] = g.name(identifier(t.Name), "Type")
+		}
+	}
+}
+
+// name reserves a unique Go identifier, adding the suffix and then a number
+// when it is already used.
+func (g *generator) name(name, suffix string) string {
+	if !g.names[name] {
+		g.names[name] = true
+		return name
+	}
+	candidate := name + suffix
+	for i := 2; g.names[candidate]; i++ {
+		candidate = name + suffix + strconv.Itoa(i)
+	}
+	g.names[candidate] = true
+	return candidate
+}
+
+func (g *generator) types() {
+	for _, s := range g.defs.Types.Schemas {
+		for _, t := range s.SimpleTypes {
+			g.simpleType(g.simple[wsdl.QName{Space: s.TargetNamespace, Local: t.Name}], t)
+		}
+		for _, t := range s.ComplexTypes {
+			g.complexType(g.complex[wsdl.QName{Space: s.TargetNamespace, Local: t.Name}], t, s)
+		}
+		for _, e := range s.Elements {
+			g.element(g.elements[wsdl.QName{Space: s.TargetNamespace, Local: e.Name}], e, s)
+		}
+	}
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		next()
+	}
+}
+
+// goType returns the Go type of a schema type and whether it is a struct.
+func (g *generator) goType(q wsdl.QName) (string, bool) {
+	if q.Space == wsdl.XSDNamespace {
+		switch q.Local {
+		case "base64Binary":
+			g.helpers[base64Type] = true
+			return base64Type, false
+		case "anyType":
+			g.helpers[anyType] = true
+			return anyType, true
+		}
+		if t, ok := xsdTypes[q.Local]; ok {
+			return t, false
+		}
+	}
+	if t, ok := g.complex[q]; ok {
+		return t, true
+	}
+	if t, ok := g.simple[q]; ok {
+		return t, false
+	}
+	g.warnf("type %s not found, generated as string", q)
+	return "string", false
+}
+
+// baseType returns the Go type a simple type derives from.
+func (g *generator) baseType(st *wsdl.SimpleType) string {
+	if st.Restriction != nil && !st.Restriction.Base.IsZero() {
+		t, _ := g.goType(st.Restriction.Base)
+		return t
+	}
+	return "string"
+}
+
+func (g *generator) simpleType(name string, st *wsdl.SimpleType) {
+	base := g.baseType(st)
+	g.printf("\n%stype %s %s\n", comment(st.Doc, name+" is the "+localName(st, name)+" simple type."), name, base)
+	if st.Restriction == nil || len(st.Restriction.Enumerations) == 0 {
+		return
+	}
+	g.printf("\n// Values of %s.\nconst (\n", name)
+	seen := map[string]bool{}
+	for _, e := range st.Restriction.Enumerations {
+		constant := name + identifier(e.Value)
+		for i := 2; seen[constant] || (g.names[constant] && constant != name); i++ {
+			constant = name + identifier(e.Value) + strconv.Itoa(i)
+		}
+		seen[constant] = true
+		g.names[constant] = true
+		value := strconv.Quote(e.Value)
+		if numeric(base) {
+			value = e.Value
+		}
+		g.printf("\t%s %s = %s\n", constant, name, value)
+	}
+	g.printf(")\n")
+}
+
+// numeric reports whether the Go type is a built-in number type.
+func numeric(t string) bool {
+	switch t {
+	case "float32", "float64", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+func localName(st *wsdl.SimpleType, fallback string) string {
+	if st.Name != "" {
+		return st.Name
+	}
+	return fallback
+}
+
+func (g *generator) complexType(name string, ct *wsdl.ComplexType, s *wsdl.Schema) {
+	doc := ct.Doc
+	if doc == "" {
+		doc = name + " is the " + ct.Name + " complex type."
+	}
+	g.printf("\n%stype %s struct {\n", comment(doc, ""), name)
+	g.fields(name, ct, s, map[string]bool{})
+	g.printf("}\n")
+}
+
+func (g *generator) element(name string, e *wsdl.Element, s *wsdl.Schema) {
+	doc := e.Doc
+	if doc == "" {
+		doc = name + " is the " + e.Name + " element."
+	}
+	g.imports["encoding/xml"] = true
+	g.printf("\n%stype %s struct {\n", comment(doc, ""), name)
+	g.printf("\tXMLName xml.Name `xml:\"%s %s\"`\n", s.TargetNamespace, e.Name)
+	switch {
+	case e.ComplexType != nil:
+		g.fields(name, e.ComplexType, s, map[string]bool{"XMLName": true})
+	case !e.Type.IsZero():
+		t, isStruct := g.goType(e.Type)
+		if isStruct && t != anyType {
+			g.printf("\t%s\n", t)
+		} else if isStruct {
+			g.printf("\tContent string `xml:\",innerxml\"`\n")
+		} else {
+			g.printf("\tValue %s `xml:\",chardata\"`\n", t)
+		}
+	case e.SimpleType != nil:
+		g.printf("\tValue %s `xml:\",chardata\"`\n", g.baseType(e.SimpleType))
+	}
+	g.printf("}\n")
+	if !s.Qualified() && hasContent(e) {
+		g.prefixed(name, s.TargetNamespace, e.Name)
+	}
+}
+
+// hasContent reports whether an element has child elements whose
+// qualification depends on the namespace prefix of the element.
+func hasContent(e *wsdl.Element) bool {
+	return e.ComplexType != nil || (!e.Type.IsZero() && e.Type.Space != wsdl.XSDNamespace)
+}
+
+// prefixed writes a MarshalXML method that encodes the element with a
+// namespace prefix, so that its unqualified children are not in the
+// namespace of the element.
+func (g *generator) prefixed(name, namespace, local string) {
+	g.imports["encoding/xml"] = true
+	g.printf(`
+// MarshalXML method encodes %[1]s with a namespace prefix, the local
+// elements of its schema are unqualified.
+func (v *%[1]s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type element %[1]s
+	start.Name = xml.Name{Local: "ns:%[3]s"}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:ns"}, Value: %[2]q})
+	return e.EncodeElement((*element)(v), start)
+}
+`, name, namespace, local)
+}
+
+// fields writes the struct fields of a complex type.
+func (g *generator) fields(parent string, ct *wsdl.ComplexType, s *wsdl.Schema, seen map[string]bool) {
+	var content *wsdl.Derivation
+	if ct.ComplexContent != nil {
+		content = ct.ComplexContent.Extension
+		if content == nil {
+			content = ct.ComplexContent.Restriction
+		} else if content.Base.Space != wsdl.XSDNamespace || content.Base.Local != "anyType" {
+			base, _ := g.goType(content.Base)
+			seen[base] = true
+			g.printf("\t%s\n", base)
+		}
+	}
+	if ct.SimpleContent != nil {
+		derivation := ct.SimpleContent.Extension
+		if derivation == nil {
+			derivation = ct.SimpleContent.Restriction
+		}
+		if derivation != nil {
+			base, isStruct := g.goType(derivation.Base)
+			if isStruct {
+				seen[base] = true
+				g.printf("\t%s\n", base)
+			} else {
+				seen["Value"] = true
+				g.printf("\tValue %s `xml:\",chardata\"`\n", base)
+			}
+			g.attributes(parent, derivation.Attributes, derivation.AttributeGroups, seen)
+		}
+		return
+	}
+
+	if content != nil {
+		g.particles(parent, s, seen, content.Sequence, content.Choice, content.All, content.Group)
+		g.attributes(parent, content.Attributes, content.AttributeGroups, seen)
+	}
+	g.particles(parent, s, seen, ct.Sequence, ct.Choice, ct.All, ct.Group)
+	g.attributes(parent, ct.Attributes, ct.AttributeGroups, seen)
+	if ct.Mixed && !seen["Text"] {
+		seen["Text"] = true
+		g.printf("\tText string `xml:\",chardata\"`\n")
+	}
+}
+
+func (g *generator) particles(parent string, s *wsdl.Schema, seen map[string]bool, sequence, choice, all *wsdl.ModelGroup, group *wsdl.GroupRef) {
+	g.modelGroup(parent, s, seen, sequence, false, false)
+	g.modelGroup(parent, s, seen, choice, true, false)
+	g.modelGroup(parent, s, seen, all, false, false)
+	if group != nil {
+		g.groupRef(parent, s, seen, group, false, false)
+	}
+}
+
+func (g *generator) groupRef(parent string, s *wsdl.Schema, seen map[string]bool, ref *wsdl.GroupRef, optional, repeated bool) {
+	group := g.defs.Group(ref.Ref)
+	if group == nil {
+		g.warnf("group %s not found", ref.Ref)
+		return
+	}
+	optional = optional || ref.MinOccurs == "0"
+	repeated = repeated || isRepeated(ref.MaxOccurs)
+	g.modelGroup(parent, group.Schema, seen, group.Sequence, optional, repeated)
+	g.modelGroup(parent, group.Schema, seen, group.Choice, true, repeated)
+	g.modelGroup(parent, group.Schema, seen, group.All, optional, repeated)
+}
+
+func (g *generator) modelGroup(parent string, s *wsdl.Schema, seen map[string]bool, mg *wsdl.ModelGroup, optional, repeated bool) {
+	if mg == nil {
+		return
+	}
+	optional = optional || mg.MinOccurs == "0"
+	repeated = repeated || isRepeated(mg.MaxOccurs)
+	for _, p := range mg.Particles {
+		switch {
+		case p.Element != nil:
+			g.field(parent, s, seen, p.Element, optional, repeated)
+		case p.Sequence != nil:
+			g.modelGroup(parent, s, seen, p.Sequence, optional, repeated)
+		case p.Choice != nil:
+			g.modelGroup(parent, s, seen, p.Choice, true, repeated)
+		case p.Group != nil:
+			g.groupRef(parent, s, seen, p.Group, optional, repeated)
+		case p.Any != nil && !seen["Any"]:
+			seen["Any"] = true
+			g.helpers[anyType] = true
+			g.printf("\tAny []%s `xml:\",any\"`\n", anyType)
+		}
+	}
+}
+
+func isRepeated(maxOccurs string) bool {
+	return maxOccurs != "" && maxOccurs != "0" && maxOccurs != "1"
+}
+
+// field writes the struct field of a local element or element reference.
+func (g *generator) field(parent string, s *wsdl.Schema, seen map[string]bool, e *wsdl.Element, optional, repeated bool) {
+	optional = optional || e.Optional()
+	repeated = repeated || e.Repeated()
+
+	name, tag := e.Name, e.Name
+	var typ string
+	var isStruct bool
+	switch {
+	case !e.Ref.IsZero():
+		ref := g.defs.Element(e.Ref)
+		if ref == nil {
+			g.warnf("element %s not found", e.Ref)
+			return
+		}
+		name, tag = ref.Name, e.Ref.Space+" "+ref.Name
+		if ref.Type.IsZero() {
+			typ, isStruct = g.elements[e.Ref], true
+		} else {
+			typ, isStruct = g.goType(ref.Type)
+		}
+	case e.ComplexType != nil:
+		typ, isStruct = g.name(parent+identifier(e.Name), "Type"), true
+		local, element, ct := typ, e.Name, e.ComplexType
+		g.pending = append(g.pending, func() {
+			g.printf("\n// %s is the type of the %s element of %s.\ntype %s struct {\n", local, element, parent, local)
+			g.fields(local, ct, s, map[string]bool{})
+			g.printf("}\n")
+		})
+	case e.SimpleType != nil:
+		if e.SimpleType.Restriction != nil && len(e.SimpleType.Restriction.Enumerations) > 0 {
+			typ = g.name(parent+identifier(e.Name), "Type")
+			local, st := typ, e.SimpleType
+			g.pending = append(g.pending, func() {
+				g.simpleType(local, st)
+			})
+		} else {
+			typ = g.baseType(e.SimpleType)
+		}
+	case !e.Type.IsZero():
+		typ, isStruct = g.goType(e.Type)
+	default:
+		typ = "string"
+	}
+
+	fieldName := identifier(name)
+	for i := 2; seen[fieldName]; i++ {
+		fieldName = identifier(name) + strconv.Itoa(i)
+	}
+	seen[fieldName] = true
+
+	switch {
+	case repeated:
+		typ = "[]" + typ
+	case isStruct:
+		typ = "*" + typ
+	}
+	if optional && !isStruct {
+		tag += ",omitempty"
+	}
+	g.printf("\t%s %s `xml:\"%s\"`\n", fieldName, typ, tag)
+}
+
+func (g *generator) attributes(parent string, attrs []*wsdl.Attribute, groups []*wsdl.AttributeGroupRef, seen map[string]bool) {
+	for _, a := range attrs {
+		name, tag := a.Name, a.Name
+		typ := "string"
+		switch {
+		case !a.Ref.IsZero():
+			name, tag = a.Ref.Local, a.Ref.Space+" "+a.Ref.Local
+		case !a.Type.IsZero():
+			typ, _ = g.goType(a.Type)
+		case a.SimpleType != nil:
+			typ = g.baseType(a.SimpleType)
+		}
+		fieldName := identifier(name)
+		if seen[fieldName] {
+			fieldName += "Attr"
+		}
+		for i := 2; seen[fieldName]; i++ {
+			fieldName = identifier(name) + "Attr" + strconv.Itoa(i)
+		}
+		seen[fieldName] = true
+		tag += ",attr"
+		if a.Use != "required" {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `xml:\"%s\"`\n", fieldName, typ, tag)
+	}
+	for _, ref := range groups {
+		group := g.defs.AttributeGroup(ref.Ref)
+		if group == nil {
+			g.warnf("attribute group %s not found", ref.Ref)
+			continue
+		}
+		g.attributes(parent, group.Attributes, group.AttributeGroups, seen)
+	}
+}
+
+// identifier returns the exported Go identifier of an XML name.
+func identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	id := b.String()
+	if id == "" {
+		return "Value"
+	}
+	if unicode.IsDigit(rune(id[0])) {
+		return "N" + id
+	}
+	return id
+}
+
+// comment formats documentation as a Go comment.
+func comment(doc, fallback string) string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		doc = fallback
+	}
+	if doc == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(doc, "\n") {
+		b.WriteString("// " + strings.TrimSpace(line) + "\n")
+	}
+	return b.String()
+}
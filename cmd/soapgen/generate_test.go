@@ -0,0 +1,144 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/mencosk/soap/wsdl"
+)
+
+func generateFile(t *testing.T, name string) (string, []string) {
+	t.Helper()
+	defs, err := wsdl.ParseFile(name)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	src, warnings, err := Generate(defs, "service")
+	if err != nil {
+		t.Fatalf("Generate() error = %v\n%s", err, src)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "service.go", src, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v", err)
+	}
+	return string(src), warnings
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name string
+		wsdl string
+		want []string
+	}{
+		{
+			name: "Document literal",
+			wsdl: "../../wsdl/testdata/numberconversion.wsdl",
+			want: []string{
+				"// Code generated by soapgen. DO NOT EDIT.",
+				"LanguageEs Language = \"es\"",
+				"XMLName  xml.Name `xml:\"http://www.dataaccess.com/webservicesserver/ NumberToWords\"`",
+				"UbiNum   uint64   `xml:\"ubiNum\"`",
+				"Language Language `xml:\"language,omitempty\"`",
+				"Reasons []string `xml:\"Reasons\"`",
+				"Code    int32    `xml:\"code,attr\"`",
+				"type InvalidNumberType struct",
+				"\tInvalidNumberType\n",
+				"func NewNumberConversionSoap12(client *soap.Client, url string) *NumberConversionSoap12",
+				"func (c *NumberConversionSoap) NumberToWords(ctx context.Context, request *NumberToWords) (*NumberToWordsResponse, error)",
+				"SetSOAPVersion(soap.SOAP12).",
+				"SetSOAPAction(\"http://www.dataaccess.com/webservicesserver/NumberToWords\").",
+				"SetFaultDetail(&InvalidNumber{}).",
+			},
+		},
+		{
+			name: "Imported schemas",
+			wsdl: "../../wsdl/testdata/import/service.wsdl",
+			want: []string{
+				"type Order struct {\n\tEntity\n",
+				"Order   *Order   `xml:\"order\"`",
+				"type OrderID string",
+				"url = \"http://localhost:8080/orders\"",
+			},
+		},
+		{
+			name: "Rpc literal",
+			wsdl: "testdata/calculator.wsdl",
+			want: []string{
+				"PrecisionN2 Precision = 2",
+				"Operand   []float64        `xml:\"operand\"`\n\tPrecision Precision        `xml:\"precision,omitempty\"`",
+				"Options   *OperandsOptions `xml:\"options\"`",
+				"Id        string           `xml:\"id,attr\"`",
+				"Trace     bool             `xml:\"trace,attr,omitempty\"`",
+				"OperandsRoundingHalfEven OperandsRounding = \"half-even\"",
+				"Signature Base64Binary `xml:\"signature\"`",
+				"Any       []AnyType    `xml:\",any\"`",
+				"XMLName  xml.Name  `xml:\"urn:example:calculator Add\"`",
+				"start.Name = xml.Name{Local: \"ns:AddResponse\"}",
+				"func (v *Overflow) MarshalXML(e *xml.Encoder, start xml.StartElement) error",
+				"func (c *CalculatorPort) Reset(ctx context.Context, request *ResetRequest) error",
+				"type Base64Binary []byte",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, warnings := generateFile(t, tt.wsdl)
+			if len(warnings) > 0 {
+				t.Errorf("Generate() warnings = %v", warnings)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(src, want) {
+					t.Errorf("Generate() missing %q in\n%s", want, src)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Encoded(t *testing.T) {
+	defs, err := wsdl.Parse(strings.NewReader(`<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"
+    xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/" xmlns:tns="urn:test" targetNamespace="urn:test">
+  <message name="Ping"/>
+  <portType name="PingPortType"><operation name="Ping"><input message="tns:Ping"/></operation></portType>
+  <binding name="PingBinding" type="tns:PingPortType">
+    <soap:binding style="rpc"/>
+    <operation name="Ping"><input><soap:body use="encoded"/></input></operation>
+  </binding>
+  <service name="Ping"><port name="PingPort" binding="tns:PingBinding"><soap:address location="http://localhost/"/></port></service>
+</definitions>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	src, warnings, err := Generate(defs, "ping")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "encoded") {
+		t.Errorf("Generate() warnings = %v, want encoded use warning", warnings)
+	}
+	if strings.Contains(string(src), "func (c *PingPort) Ping") {
+		t.Errorf("Generate() generated the skipped operation\n%s", src)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "NumberToWords", want: "NumberToWords"},
+		{name: "ubiNum", want: "UbiNum"},
+		{name: "half-even", want: "HalfEven"},
+		{name: "order_id", want: "OrderId"},
+		{name: "2", want: "N2"},
+		{name: "", want: "Value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identifier(tt.name); got != tt.want {
+				t.Errorf("identifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
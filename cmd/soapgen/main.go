@@ -0,0 +1,64 @@
+// Command soapgen generates Go types and clients from a WSDL 1.1 document and
+// its XML Schemas.
+//
+// It emits one struct per schema element and complex type, one string type
+// with constants per enumeration, and one client per SOAP port with a method
+// per operation that calls the service through soap.Client.
+//
+//	soapgen -wsdl numberconversion.wsdl -package numberconversion -o numberconversion.go
+//
+// or from a go:generate directive
+//
+//	//go:generate go run github.com/mencosk/soap/cmd/soapgen -wsdl service.wsdl -package service -o service.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mencosk/soap/wsdl"
+)
+
+func main() {
+	var in, out, pkg string
+	flag.StringVar(&in, "wsdl", "", "WSDL `file` to generate the code from")
+	flag.StringVar(&out, "o", "", "output `file`, the standard output if empty")
+	flag.StringVar(&pkg, "package", "service", "`name` of the generated package")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: soapgen -wsdl file [-package name] [-o file]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if in == "" {
+		in = flag.Arg(0)
+	}
+	if in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	defs, err := wsdl.ParseFile(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "soapgen:", err)
+		os.Exit(1)
+	}
+	src, warnings, err := Generate(defs, pkg)
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "soapgen: warning:", w)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "soapgen:", err)
+		os.Exit(1)
+	}
+
+	if out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "soapgen:", err)
+		os.Exit(1)
+	}
+}
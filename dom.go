@@ -0,0 +1,385 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// xmlNamespace is the namespace bound to the reserved `xml` prefix.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+var errNoRoot = errors.New("soap: document has no root element")
+
+// xmlElement is an element of the XML tree used to sign, verify and
+// canonicalize envelopes. Unlike encoding/xml it keeps the namespace
+// prefixes and declarations of the document.
+type xmlElement struct {
+	prefix   string
+	local    string
+	attrs    []xmlAttr
+	children []interface{}
+	parent   *xmlElement
+}
+
+// xmlAttr is an attribute, namespace declarations included.
+type xmlAttr struct {
+	prefix string
+	local  string
+	value  string
+}
+
+// The other nodes of the tree.
+type (
+	xmlText     string
+	xmlComment  string
+	xmlProcInst xml.ProcInst
+)
+
+// isNamespace reports whether the attribute is a namespace declaration.
+func (a xmlAttr) isNamespace() bool {
+	return a.prefix == "xmlns" || (a.prefix == "" && a.local == "xmlns")
+}
+
+// declared returns the prefix declared by a namespace declaration, empty
+// for the default namespace.
+func (a xmlAttr) declared() string {
+	if a.prefix == "xmlns" {
+		return a.local
+	}
+	return ""
+}
+
+// parseXML reads a document into a tree and returns its root element.
+func parseXML(data []byte) (*xmlElement, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *xmlElement
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &xmlElement{prefix: t.Name.Space, local: t.Name.Local, parent: current}
+			for _, a := range t.Attr {
+				e.attrs = append(e.attrs, xmlAttr{prefix: a.Name.Space, local: a.Name.Local, value: a.Value})
+			}
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("soap: document has several root elements")
+				}
+				root = e
+			} else {
+				current.children = append(current.children, e)
+			}
+			current = e
+		case xml.EndElement:
+			if current == nil {
+				return nil, errors.New("soap: unexpected end element")
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.appendText(string(t))
+			}
+		case xml.Comment:
+			if current != nil {
+				current.children = append(current.children, xmlComment(t))
+			}
+		case xml.ProcInst:
+			if current != nil {
+				current.children = append(current.children, xmlProcInst(t.Copy()))
+			}
+		}
+	}
+	if root == nil {
+		return nil, errNoRoot
+	}
+	if current != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return root, nil
+}
+
+func (e *xmlElement) appendText(text string) {
+	if n := len(e.children); n > 0 {
+		if last, ok := e.children[n-1].(xmlText); ok {
+			e.children[n-1] = last + xmlText(text)
+			return
+		}
+	}
+	e.children = append(e.children, xmlText(text))
+}
+
+// lookupNamespace returns the namespace bound to the prefix in the scope of
+// the element, the default namespace for an empty prefix.
+func (e *xmlElement) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+	for n := e; n != nil; n = n.parent {
+		for _, a := range n.attrs {
+			if a.isNamespace() && a.declared() == prefix {
+				return a.value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// namespace returns the namespace of the element.
+func (e *xmlElement) namespace() string {
+	ns, _ := e.lookupNamespace(e.prefix)
+	return ns
+}
+
+// is reports whether the element has the namespace and local name.
+func (e *xmlElement) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// attrNamespace returns the namespace of an attribute of the element,
+// unprefixed attributes have no namespace.
+func (e *xmlElement) attrNamespace(a xmlAttr) string {
+	if a.prefix == "" {
+		return ""
+	}
+	ns, _ := e.lookupNamespace(a.prefix)
+	return ns
+}
+
+// attr returns the value of the attribute with the namespace and local name.
+func (e *xmlElement) attr(namespace, local string) (string, bool) {
+	for _, a := range e.attrs {
+		if !a.isNamespace() && a.local == local && e.attrNamespace(a) == namespace {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// setAttr sets the attribute with the prefix, declared by the caller.
+func (e *xmlElement) setAttr(prefix, local, value string) {
+	for i, a := range e.attrs {
+		if a.prefix == prefix && a.local == local {
+			e.attrs[i].value = value
+			return
+		}
+	}
+	e.attrs = append(e.attrs, xmlAttr{prefix: prefix, local: local, value: value})
+}
+
+// declare binds the namespace to a prefix in the scope of the element and
+// returns the prefix, reusing one already bound to the namespace.
+func (e *xmlElement) declare(prefix, namespace string) string {
+	for candidate, i := prefix, 1; ; i++ {
+		ns, ok := e.lookupNamespace(candidate)
+		if ok && ns == namespace {
+			return candidate
+		}
+		if !ok {
+			e.attrs = append(e.attrs, xmlAttr{prefix: "xmlns", local: candidate, value: namespace})
+			return candidate
+		}
+		candidate = prefix + strings.Repeat("x", i)
+	}
+}
+
+// elements returns the child elements.
+func (e *xmlElement) elements() []*xmlElement {
+	var children []*xmlElement
+	for _, c := range e.children {
+		if child, ok := c.(*xmlElement); ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// child returns the first child element with the namespace and local name.
+func (e *xmlElement) child(namespace, local string) *xmlElement {
+	for _, c := range e.elements() {
+		if c.is(namespace, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// appendChild appends the element to the children.
+func (e *xmlElement) appendChild(child *xmlElement) {
+	child.parent = e
+	e.children = append(e.children, child)
+}
+
+// insertChild inserts the element before the child at index i.
+func (e *xmlElement) insertChild(i int, child *xmlElement) {
+	child.parent = e
+	e.children = append(e.children, nil)
+	copy(e.children[i+1:], e.children[i:])
+	e.children[i] = child
+}
+
+// text returns the concatenated text content of the element.
+func (e *xmlElement) text() string {
+	var b strings.Builder
+	for _, c := range e.children {
+		switch n := c.(type) {
+		case xmlText:
+			b.WriteString(string(n))
+		case *xmlElement:
+			b.WriteString(n.text())
+		}
+	}
+	return b.String()
+}
+
+// find returns the first element of the subtree matching the predicate,
+// the element itself included.
+func (e *xmlElement) find(match func(*xmlElement) bool) *xmlElement {
+	if match(e) {
+		return e
+	}
+	for _, c := range e.elements() {
+		if found := c.find(match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// qname returns the prefixed name.
+func qname(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// bytes returns the serialized subtree.
+func (e *xmlElement) bytes() []byte {
+	var buf bytes.Buffer
+	e.write(&buf)
+	return buf.Bytes()
+}
+
+func (e *xmlElement) write(buf *bytes.Buffer) {
+	name := qname(e.prefix, e.local)
+	buf.WriteString("<" + name)
+	for _, a := range e.attrs {
+		buf.WriteString(" " + qname(a.prefix, a.local) + `="`)
+		escapeAttr(buf, a.value)
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	e.writeContent(buf)
+	buf.WriteString("</" + name + ">")
+}
+
+// content returns the serialized children of the element.
+func (e *xmlElement) content() []byte {
+	var buf bytes.Buffer
+	e.writeContent(&buf)
+	return buf.Bytes()
+}
+
+func (e *xmlElement) writeContent(buf *bytes.Buffer) {
+	for _, c := range e.children {
+		switch n := c.(type) {
+		case *xmlElement:
+			n.write(buf)
+		case xmlText:
+			escapeText(buf, string(n))
+		case xmlComment:
+			buf.WriteString("<!--" + string(n) + "-->")
+		case xmlProcInst:
+			writeProcInst(buf, n)
+		}
+	}
+}
+
+// parseContent reads the serialized children of an element in the scope of
+// its namespace declarations and sets them as its children.
+func (e *xmlElement) parseContent(data []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString("<content")
+	seen := map[string]bool{}
+	for n := e; n != nil; n = n.parent {
+		for _, a := range n.attrs {
+			if prefix := a.declared(); a.isNamespace() && !seen[prefix] {
+				seen[prefix] = true
+				buf.WriteString(" " + qname(a.prefix, a.local) + `="`)
+				escapeAttr(&buf, a.value)
+				buf.WriteString(`"`)
+			}
+		}
+	}
+	buf.WriteString(">")
+	buf.Write(data)
+	buf.WriteString("</content>")
+
+	content, err := parseXML(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	e.children = content.children
+	for _, child := range e.elements() {
+		child.parent = e
+	}
+	return nil
+}
+
+func writeProcInst(buf *bytes.Buffer, p xmlProcInst) {
+	buf.WriteString("<?" + p.Target)
+	if len(p.Inst) > 0 {
+		buf.WriteString(" ")
+		buf.Write(p.Inst)
+	}
+	buf.WriteString("?>")
+}
+
+// escapeText escapes character data as required by the canonical XML.
+func escapeText(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+// escapeAttr escapes an attribute value as required by the canonical XML.
+func escapeAttr(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
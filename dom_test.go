@@ -0,0 +1,76 @@
+package soap
+
+import (
+	"testing"
+)
+
+func Test_parseXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "Keep prefixes and declarations",
+			document: `<?xml version="1.0"?><s:Envelope xmlns:s="urn:s"><s:Body a="1 &amp; 2"><Op xmlns="urn:op">x &lt; y<!--c--></Op></s:Body></s:Envelope>`,
+			want:     `<s:Envelope xmlns:s="urn:s"><s:Body a="1 &amp; 2"><Op xmlns="urn:op">x &lt; y<!--c--></Op></s:Body></s:Envelope>`,
+		},
+		{
+			name:     "Expand empty elements",
+			document: `<a><b/></a>`,
+			want:     `<a><b></b></a>`,
+		},
+		{
+			name:     "No root element",
+			document: `<?xml version="1.0"?>`,
+			wantErr:  true,
+		},
+		{
+			name:     "Unclosed element",
+			document: `<a><b></b>`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseXML([]byte(tt.document))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseXML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := string(e.bytes()); got != tt.want {
+				t.Errorf("bytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_xmlElement_namespaces(t *testing.T) {
+	root, err := parseXML([]byte(`<s:Envelope xmlns:s="urn:s" xmlns:wsu="urn:other"><s:Body xmlns="urn:op"><Op s:id="1"/></s:Body></s:Envelope>`))
+	if err != nil {
+		t.Fatalf("parseXML() error = %v", err)
+	}
+	body := root.child("urn:s", "Body")
+	if body == nil {
+		t.Fatalf("child() = nil, want Body")
+	}
+	op := body.child("urn:op", "Op")
+	if op == nil {
+		t.Fatalf("child() = nil, want Op in the default namespace")
+	}
+	if v, ok := op.attr("urn:s", "id"); !ok || v != "1" {
+		t.Errorf("attr() = %v, %v, want 1", v, ok)
+	}
+	if got := op.declare("s", "urn:s"); got != "s" {
+		t.Errorf("declare() = %v, want the bound prefix s", got)
+	}
+	if got := op.declare("wsu", WSUNamespace); got != "wsux" {
+		t.Errorf("declare() = %v, want wsux for a prefix bound to another namespace", got)
+	}
+	if ns, _ := op.lookupNamespace("wsux"); ns != WSUNamespace {
+		t.Errorf("lookupNamespace() = %v, want %v", ns, WSUNamespace)
+	}
+}
@@ -0,0 +1,141 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Dump method returns the HTTP request sent by the last attempt of the current request, its request line,
+// headers and body, with the envelope indented and the other multipart parts summarized. It returns an
+// empty string before the request is sent, and the body of a streamed request is not kept.
+//		req := client.R().
+//			SetPayloadRequest(&NumberToWords{Number: "777"})
+//		if _, err := req.Call(); err != nil {
+//			fmt.Println(req.Dump())
+//		}
+//
+func (r *Request) Dump() string {
+	req := r.RawRequest
+	if req == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", req.Method, req.URL.RequestURI())
+	fmt.Fprintf(&b, "Host: %s\r\n", req.URL.Host)
+	if req.ContentLength > 0 {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n", req.ContentLength)
+	}
+	req.Header.Write(&b)
+	b.WriteString("\r\n")
+	if req.GetBody == nil {
+		b.WriteString("[streamed body]\n")
+		return b.String()
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return b.String()
+	}
+	defer body.Close()
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return b.String()
+	}
+	dumpBody(&b, req.Header.Get("Content-Type"), data)
+	return b.String()
+}
+
+// Dump method returns the HTTP response received, its status line, headers and body as received, before
+// the after-receive hooks, with the envelope indented and the other multipart parts summarized. The body
+// of a streamed or deferred response is not kept.
+//		resp, err := client.R().
+//			SetPayloadRequest(&NumberToWords{Number: "777"}).
+//			Call()
+//		if err != nil && resp != nil {
+//			fmt.Println(resp.Dump())
+//		}
+//
+func (r *Response) Dump() string {
+	resp := r.RawResponse
+	if resp == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\r\n", resp.Proto, resp.Status)
+	resp.Header.Write(&b)
+	b.WriteString("\r\n")
+	if r.body == nil {
+		b.WriteString("[streamed body]\n")
+		return b.String()
+	}
+	dumpBody(&b, resp.Header.Get("Content-Type"), r.body)
+	return b.String()
+}
+
+// dumpBody writes the indented envelope of the body, followed by a line for
+// each other part of a multipart body.
+func dumpBody(b *strings.Builder, contentType string, data []byte) {
+	envelope, parts, err := readMultipart(contentType, data)
+	if err != nil {
+		envelope, parts = data, nil
+	}
+	b.Write(indentXML(envelope))
+	b.WriteString("\n")
+	for _, part := range parts {
+		fmt.Fprintf(b, "[part <%s> %s, %d bytes]\n", part.contentID, part.header.Get("Content-Type"), len(part.data))
+	}
+}
+
+// indentXML returns the document with an element per line, indented by its
+// depth, and the elements with only text on a single line. It is returned
+// unchanged when it is not well-formed.
+func indentXML(data []byte) []byte {
+	var out bytes.Buffer
+	d := xml.NewDecoder(bytes.NewReader(data))
+	depth, offset, inline := 0, int64(0), false
+	newline := func() {
+		if out.Len() > 0 {
+			out.WriteString("\n" + strings.Repeat("  ", depth))
+		}
+	}
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF && depth == 0 {
+			return out.Bytes()
+		}
+		if err != nil {
+			return data
+		}
+		raw := data[offset:d.InputOffset()]
+		offset = d.InputOffset()
+		switch t := tok.(type) {
+		case xml.StartElement:
+			newline()
+			out.Write(raw)
+			depth++
+			inline = true
+		case xml.EndElement:
+			depth--
+			if len(raw) == 0 {
+				// The end of a self-closing element.
+				inline = false
+				continue
+			}
+			if !inline {
+				newline()
+			}
+			out.Write(raw)
+			inline = false
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				out.Write(raw)
+			}
+		default:
+			newline()
+			out.Write(raw)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package soap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequest_Dump(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	envelope := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <NumberToWords xmlns="http://www.dataaccess.com/webservicesserver/">
+      <ubiNum>7</ubiNum>
+    </NumberToWords>
+  </soap:Body>
+</soap:Envelope>
+`
+
+	tests := []struct {
+		name    string
+		request func(r *Request) *Request
+		want    []string
+	}{
+		{
+			name: "Envelope",
+			request: func(r *Request) *Request {
+				return r.SetSOAPAction("urn:NumberToWords").SetHeader("X-Partner", "numbers")
+			},
+			want: []string{
+				"POST /numbers?version=2 HTTP/1.1\r\nHost: " + host + "\r\nContent-Length: 211\r\n",
+				"Content-Type: text/xml; charset=utf-8\r\n",
+				"Soapaction: \"urn:NumberToWords\"\r\n",
+				"X-Partner: numbers\r\n\r\n" + envelope,
+			},
+		},
+		{
+			name: "Attachment",
+			request: func(r *Request) *Request {
+				return r.AddAttachment("invoice@example.com", "application/pdf", strings.NewReader("%PDF-1.4"))
+			},
+			want: []string{"Content-Type: multipart/related; ", "\r\n\r\n" + envelope + "[part <invoice@example.com> application/pdf, 8 bytes]\n"},
+		},
+		{
+			name: "Streamed",
+			request: func(r *Request) *Request {
+				return r.SetStreaming(true)
+			},
+			want: []string{"POST /numbers?version=2 HTTP/1.1\r\n", "\r\n\r\n[streamed body]\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New().R().
+				SetUrl(server.URL + "/numbers?version=2").
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"})
+			if got := r.Dump(); got != "" {
+				t.Errorf("Dump() before Call = %q, want empty", got)
+			}
+			resp, err := tt.request(r).Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+			got := resp.Request.Dump()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Dump() = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResponse_Dump(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>invalid</faultstring><detail/></soap:Fault></soap:Body></soap:Envelope>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Header().Set("X-Request-Id", "42")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		streaming bool
+		want      []string
+	}{
+		{
+			name: "Fault",
+			want: []string{
+				"HTTP/1.1 500 Internal Server Error\r\n",
+				"Content-Type: text/xml; charset=utf-8\r\n",
+				"X-Request-Id: 42\r\n\r\n" + `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>invalid</faultstring>
+      <detail/>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>
+`,
+			},
+		},
+		{
+			name:      "Streamed",
+			streaming: true,
+			want:      []string{"HTTP/1.1 500 Internal Server Error\r\n", "\r\n\r\n[streamed body]\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := New().SetStreaming(tt.streaming).R().
+				SetUrl(server.URL).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				Call()
+			if resp == nil {
+				t.Fatal("Call() response = nil")
+			}
+			got := resp.Dump()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Dump() = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+	if got := (&Response{}).Dump(); got != "" {
+		t.Errorf("Dump() without response = %q, want empty", got)
+	}
+}
+
+func Test_indentXML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "Nested elements",
+			data: `<?xml version="1.0"?><a xmlns:p="urn:p"><p:b x="1">text</p:b><c/><d></d></a>`,
+			want: "<?xml version=\"1.0\"?>\n<a xmlns:p=\"urn:p\">\n  <p:b x=\"1\">text</p:b>\n  <c/>\n  <d></d>\n</a>",
+		},
+		{
+			name: "Indented document",
+			data: "<a>\n\t<b>&amp;<![CDATA[<x>]]></b>\n\t<!-- note -->\n</a>\n",
+			want: "<a>\n  <b>&amp;<![CDATA[<x>]]></b>\n  <!-- note -->\n</a>",
+		},
+		{
+			name: "Not XML",
+			data: "<a><b></a>",
+			want: "<a><b></a>",
+		},
+		{
+			name: "Unclosed element",
+			data: "<a><b></b>",
+			want: "<a><b></b>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(indentXML([]byte(tt.data))); got != tt.want {
+				t.Errorf("indentXML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
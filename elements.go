@@ -0,0 +1,143 @@
+package soap
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	errBodyRead  = errors.New("soap: response body already read")
+	errNoElement = errors.New("soap: no current element to decode")
+)
+
+// bodyStream is the Body of a response whose decoding was deferred, the
+// decoder being positioned after the start of its first child.
+type bodyStream struct {
+	d     *xml.Decoder
+	start *xml.StartElement
+	body  io.Closer
+}
+
+// Elements struct iterates over the elements at a path inside the Body of a
+// response, decoding them one at a time.
+//
+//	elements := resp.Elements("GetCatalogResponse/Items/Item")
+//	defer elements.Close()
+//	for elements.Next() {
+//		item := &Item{}
+//		if err := elements.Decode(item); err != nil {
+//			return err
+//		}
+//	}
+//	return elements.Err()
+type Elements struct {
+	d       *xml.Decoder
+	next    *xml.StartElement
+	current *xml.StartElement
+	path    []string
+	depth   int
+	body    io.Closer
+	done    bool
+	err     error
+}
+
+// newElements returns an iterator over the elements at the path, reading
+// the Body of the decoder from its first child.
+func newElements(d *xml.Decoder, start *xml.StartElement, path string, body io.Closer) *Elements {
+	return &Elements{
+		d:    d,
+		next: start,
+		path: strings.Split(strings.Trim(path, "/"), "/"),
+		body: body,
+		done: start == nil,
+	}
+}
+
+// Next method advances to the next element at the path, skipping the current
+// one when it was not decoded. It returns false at the end of the Body or
+// after an error, the response body being closed then.
+func (e *Elements) Next() bool {
+	if e.done || e.err != nil {
+		return false
+	}
+	if e.current != nil {
+		e.current = nil
+		if err := e.d.Skip(); err != nil {
+			return e.fail(err)
+		}
+	}
+	for {
+		var tok xml.Token
+		if e.next != nil {
+			tok, e.next = *e.next, nil
+		} else {
+			var err error
+			if tok, err = e.d.Token(); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return e.fail(err)
+			}
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if e.depth < len(e.path) && t.Name.Local == e.path[e.depth] {
+				if e.depth == len(e.path)-1 {
+					e.current = &t
+					return true
+				}
+				e.depth++
+				continue
+			}
+			if err := e.d.Skip(); err != nil {
+				return e.fail(err)
+			}
+		case xml.EndElement:
+			if e.depth == 0 {
+				e.done = true
+				e.Close()
+				return false
+			}
+			e.depth--
+		}
+	}
+}
+
+// Decode method decodes the current element into v.
+func (e *Elements) Decode(v interface{}) error {
+	if e.current == nil {
+		return errNoElement
+	}
+	start := e.current
+	e.current = nil
+	if err := e.d.DecodeElement(v, start); err != nil {
+		e.fail(err)
+		return e.err
+	}
+	return nil
+}
+
+// Err method returns the error that ended the iteration, nil at the end of
+// the Body. A failed read is an ErrRead error, any other an ErrDecode one.
+func (e *Elements) Err() error {
+	return e.err
+}
+
+// Close method ends the iteration and closes the response body.
+func (e *Elements) Close() error {
+	e.done = true
+	if e.body == nil {
+		return nil
+	}
+	body := e.body
+	e.body = nil
+	return body.Close()
+}
+
+func (e *Elements) fail(err error) bool {
+	e.err = streamError(err)
+	e.Close()
+	return false
+}
@@ -0,0 +1,173 @@
+package soap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type CatalogItem struct {
+	SKU  string   `xml:"sku,attr"`
+	Name string   `xml:"Name"`
+	Tags []string `xml:"Tag"`
+}
+
+func catalogResponse(items int) string {
+	var b strings.Builder
+	b.WriteString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><GetCatalogResponse xmlns="urn:catalog"><Total>`)
+	fmt.Fprint(&b, items)
+	b.WriteString(`</Total><Items>`)
+	for i := 0; i < items; i++ {
+		fmt.Fprintf(&b, `<Item sku="%d"><Name>item %d</Name><Tag>a</Tag><Item sku="nested"/></Item><Note>skipped</Note>`, i, i)
+	}
+	b.WriteString(`</Items></GetCatalogResponse></soap:Body></soap:Envelope>`)
+	return b.String()
+}
+
+func TestResponse_Each(t *testing.T) {
+	fault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>boom</faultstring></soap:Fault></soap:Body></soap:Envelope>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fault":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fault))
+		case "/truncated":
+			w.Write([]byte(catalogResponse(3)[:300]))
+		case "/empty":
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`))
+		default:
+			w.Write([]byte(catalogResponse(1000)))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		endpoint  string
+		path      string
+		deferred  bool
+		max       int64
+		wantItems int
+		wantErr   error
+		wantCause error
+	}{
+		{name: "Deferred decoding", path: "GetCatalogResponse/Items/Item", deferred: true, wantItems: 1000},
+		{name: "Received envelope", path: "/GetCatalogResponse/Items/Item/", wantItems: 1000},
+		{name: "Unknown path", path: "GetCatalogResponse/Item", deferred: true},
+		{name: "Empty Body", endpoint: "/empty", path: "GetCatalogResponse/Items/Item", deferred: true},
+		{name: "Truncated body", endpoint: "/truncated", path: "GetCatalogResponse/Items/Item", deferred: true, wantItems: 1, wantErr: ErrDecode},
+		{name: "Maximum response size", path: "GetCatalogResponse/Items/Item", deferred: true, max: 4096, wantErr: ErrRead, wantCause: ErrResponseTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := New().R().
+				SetUrl(server.URL + tt.endpoint).
+				SetDeferDecoding(tt.deferred).
+				SetMaxResponseSize(tt.max).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+			if tt.deferred && len(resp.PayloadResponse()) != 0 {
+				t.Errorf("PayloadResponse() = %s, want the Body left unread", resp.PayloadResponse())
+			}
+
+			item := &CatalogItem{}
+			var items int
+			err = resp.Each(tt.path, item, func() error {
+				if want := fmt.Sprint(items); item.SKU != want || item.Name != "item "+want || len(item.Tags) != 1 {
+					t.Fatalf("item = %+v, want item %v", item, want)
+				}
+				items++
+				return nil
+			})
+			if tt.wantErr == nil && err != nil || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Each() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantCause != nil && !errors.Is(err, tt.wantCause) {
+				t.Errorf("Each() error = %v, want %v", err, tt.wantCause)
+			}
+			if tt.wantErr == nil && items != tt.wantItems {
+				t.Errorf("Each() items = %v, want %v", items, tt.wantItems)
+			}
+
+			err = resp.Each(tt.path, item, func() error { return nil })
+			if tt.deferred && !errors.Is(err, ErrRead) {
+				t.Errorf("second Each() error = %v, want ErrRead", err)
+			}
+			if !tt.deferred && err != nil {
+				t.Errorf("second Each() error = %v", err)
+			}
+		})
+	}
+
+	t.Run("Fault", func(t *testing.T) {
+		resp, err := New().R().
+			SetUrl(server.URL + "/fault").
+			SetDeferDecoding(true).
+			Call()
+		if !errors.Is(err, ErrFault) || resp.Fault().CodeLocal() != "Server" {
+			t.Fatalf("Call() error = %v, want the fault", err)
+		}
+		if err := resp.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	})
+
+	t.Run("Callback error", func(t *testing.T) {
+		resp, err := New().R().
+			SetUrl(server.URL).
+			SetDeferDecoding(true).
+			Call()
+		if err != nil {
+			t.Fatalf("Call() error = %v", err)
+		}
+		stop := errors.New("stop")
+		var items int
+		err = resp.Each("GetCatalogResponse/Items/Item", &CatalogItem{}, func() error {
+			if items++; items == 10 {
+				return stop
+			}
+			return nil
+		})
+		if err != stop || items != 10 {
+			t.Errorf("Each() = %v after %v items, want %v after 10", err, items, stop)
+		}
+	})
+}
+
+func TestElements_Next(t *testing.T) {
+	resp := &Response{payloadResponse: []byte(catalogResponse(5))}
+	elements := resp.Elements("GetCatalogResponse/Items/Item")
+	var skus []string
+	for elements.Next() {
+		if len(skus) == 4 {
+			// An element that is not decoded is skipped.
+			skus = append(skus, "-")
+			continue
+		}
+		item := &CatalogItem{}
+		if err := elements.Decode(item); err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if err := elements.Decode(item); err != errNoElement {
+			t.Errorf("second Decode() error = %v, want %v", err, errNoElement)
+		}
+		skus = append(skus, item.SKU)
+	}
+	if err := elements.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+	if got := strings.Join(skus, ","); got != "0,1,2,3,-" {
+		t.Errorf("Next() elements = %v, want 0,1,2,3,-", got)
+	}
+
+	elements = (&Response{payloadResponse: []byte("<a/>")}).Elements("a")
+	if elements.Next() || !errors.Is(elements.Err(), ErrDecode) {
+		t.Errorf("Next() error = %v, want ErrDecode", elements.Err())
+	}
+}
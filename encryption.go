@@ -0,0 +1,384 @@
+package soap
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	// Register the digest of the RSA-OAEP key transport.
+	_ "crypto/sha1"
+)
+
+// XMLEncNamespace is the XML Encryption namespace.
+const XMLEncNamespace = "http://www.w3.org/2001/04/xmlenc#"
+
+// Block encryption algorithms of the XML Encryption content.
+const (
+	AES128CBC = "http://www.w3.org/2001/04/xmlenc#aes128-cbc"
+	AES256CBC = "http://www.w3.org/2001/04/xmlenc#aes256-cbc"
+	AES128GCM = "http://www.w3.org/2009/xmlenc11#aes128-gcm"
+	AES256GCM = "http://www.w3.org/2009/xmlenc11#aes256-gcm"
+)
+
+// RSAOAEP is the RSA-OAEP key transport algorithm, with SHA-1 digest and
+// mask generation.
+const RSAOAEP = "http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p"
+
+// Types of the encrypted data.
+const (
+	encryptedContent = "http://www.w3.org/2001/04/xmlenc#Content"
+	encryptedElement = "http://www.w3.org/2001/04/xmlenc#Element"
+)
+
+var blockMethods = map[string]struct {
+	keySize int
+	gcm     bool
+}{
+	AES128CBC: {16, false},
+	AES256CBC: {32, false},
+	AES128GCM: {16, true},
+	AES256GCM: {32, true},
+}
+
+var errDecrypt = errors.New("soap: decryption failed")
+
+// Encrypter struct configures the XML Encryption of the request Body
+// content. The content is encrypted with a random key, itself encrypted
+// with RSA-OAEP for the certificate of the service in a `xenc:EncryptedKey`
+// of the `wsse:Security` header. A signed Body is encrypted once signed.
+//
+//	client.SetWSSecurity(&soap.WSSecurity{
+//		Encrypter: &soap.Encrypter{
+//			Certificate: serviceCert,
+//			Algorithm:   soap.AES256GCM,
+//		},
+//	})
+type Encrypter struct {
+	// Certificate of the service, its RSA public key encrypts the content
+	// key. It is referred to by its subject key identifier or, without one,
+	// by its issuer and serial number.
+	Certificate *x509.Certificate
+	// Algorithm encrypts the Body content, defaults to AES256GCM.
+	Algorithm string
+}
+
+type encryptedKeyElement struct {
+	XMLName          xml.Name                `xml:"xenc:EncryptedKey"`
+	Xenc             string                  `xml:"xmlns:xenc,attr"`
+	Ds               string                  `xml:"xmlns:ds,attr"`
+	ID               string                  `xml:"Id,attr"`
+	EncryptionMethod encryptionMethodElement `xml:"xenc:EncryptionMethod"`
+	KeyInfo          tokenReference          `xml:"ds:KeyInfo>wsse:SecurityTokenReference"`
+	CipherValue      string                  `xml:"xenc:CipherData>xenc:CipherValue"`
+	References       []dataReference         `xml:"xenc:ReferenceList>xenc:DataReference"`
+}
+
+type encryptedDataElement struct {
+	XMLName          xml.Name                `xml:"xenc:EncryptedData"`
+	Xenc             string                  `xml:"xmlns:xenc,attr"`
+	ID               string                  `xml:"Id,attr"`
+	Type             string                  `xml:"Type,attr"`
+	EncryptionMethod encryptionMethodElement `xml:"xenc:EncryptionMethod"`
+	CipherValue      string                  `xml:"xenc:CipherData>xenc:CipherValue"`
+}
+
+type encryptionMethodElement struct {
+	Algorithm    string            `xml:"Algorithm,attr"`
+	DigestMethod *algorithmElement `xml:"ds:DigestMethod,omitempty"`
+}
+
+type dataReference struct {
+	URI string `xml:"URI,attr"`
+}
+
+type x509DataElement struct {
+	IssuerName   string `xml:"ds:X509IssuerSerial>ds:X509IssuerName"`
+	SerialNumber string `xml:"ds:X509IssuerSerial>ds:X509SerialNumber"`
+}
+
+// encrypt replaces the Body content of the envelope by its encrypted data
+// and prepends the encrypted key to the security header.
+func (e *Encrypter) encrypt(envelope, security *xmlElement) error {
+	algorithm := e.Algorithm
+	if algorithm == "" {
+		algorithm = AES256GCM
+	}
+	method, ok := blockMethods[algorithm]
+	if !ok {
+		return fmt.Errorf("soap: unsupported encryption algorithm %q", algorithm)
+	}
+	if e.Certificate == nil {
+		return errors.New("soap: encrypter needs a certificate")
+	}
+	publicKey, ok := e.Certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("soap: unsupported encryption key %T", e.Certificate.PublicKey)
+	}
+	body := envelope.child(envelope.namespace(), "Body")
+	if body == nil {
+		return errBodyNotFound
+	}
+
+	key := make([]byte, method.keySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	cipherText, err := encryptBlock(algorithm, key, body.content())
+	if err != nil {
+		return err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(crypto.SHA1.New(), rand.Reader, publicKey, key, nil)
+	if err != nil {
+		return err
+	}
+
+	data, err := xml.Marshal(&encryptedDataElement{
+		Xenc:             XMLEncNamespace,
+		ID:               "ED-1",
+		Type:             encryptedContent,
+		EncryptionMethod: encryptionMethodElement{Algorithm: algorithm},
+		CipherValue:      base64.StdEncoding.EncodeToString(cipherText),
+	})
+	if err != nil {
+		return err
+	}
+	if err := body.parseContent(data); err != nil {
+		return err
+	}
+
+	keyElement := &encryptedKeyElement{
+		Xenc: XMLEncNamespace,
+		Ds:   DSigNamespace,
+		ID:   "EK-1",
+		EncryptionMethod: encryptionMethodElement{
+			Algorithm:    RSAOAEP,
+			DigestMethod: &algorithmElement{Algorithm: DigestSHA1},
+		},
+		CipherValue: base64.StdEncoding.EncodeToString(encryptedKey),
+		References:  []dataReference{{URI: "#ED-1"}},
+	}
+	if ski := e.Certificate.SubjectKeyId; len(ski) > 0 {
+		keyElement.KeyInfo.KeyIdentifier = &securityValue{
+			EncodingType: Base64BinaryURI,
+			ValueType:    SubjectKeyIdentifierURI,
+			Value:        base64.StdEncoding.EncodeToString(ski),
+		}
+	} else {
+		keyElement.KeyInfo.X509Data = &x509DataElement{
+			IssuerName:   e.Certificate.Issuer.String(),
+			SerialNumber: e.Certificate.SerialNumber.String(),
+		}
+	}
+	if data, err = xml.Marshal(keyElement); err != nil {
+		return err
+	}
+	element, err := parseXML(data)
+	if err != nil {
+		return err
+	}
+	security.insertChild(0, element)
+	return nil
+}
+
+// decrypt replaces the encrypted data of the envelope, listed by the
+// encrypted keys of the security header, by their decrypted content.
+func decrypt(envelope *xmlElement, key crypto.Decrypter) error {
+	namespace := envelope.namespace()
+	header := envelope.child(namespace, "Header")
+	if header == nil {
+		return nil
+	}
+	security := header.child(WSSENamespace, "Security")
+	if security == nil {
+		return nil
+	}
+	for _, encryptedKey := range security.elements() {
+		if !encryptedKey.is(XMLEncNamespace, "EncryptedKey") {
+			continue
+		}
+		cek, err := decryptKey(encryptedKey, key)
+		if err != nil {
+			return err
+		}
+		list := encryptedKey.child(XMLEncNamespace, "ReferenceList")
+		if list == nil {
+			continue
+		}
+		for _, ref := range list.elements() {
+			if !ref.is(XMLEncNamespace, "DataReference") {
+				continue
+			}
+			uri, _ := ref.attr("", "URI")
+			data, err := referencedElement(envelope, uri)
+			if err != nil {
+				return err
+			}
+			if !data.is(XMLEncNamespace, "EncryptedData") {
+				return fmt.Errorf("soap: %s is not an EncryptedData", uri)
+			}
+			if err := decryptData(data, cek); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decryptKey returns the content key of an EncryptedKey.
+func decryptKey(encryptedKey *xmlElement, key crypto.Decrypter) ([]byte, error) {
+	method := encryptedKey.child(XMLEncNamespace, "EncryptionMethod")
+	if method == nil {
+		return nil, errors.New("soap: encrypted key has no EncryptionMethod")
+	}
+	if algorithm, _ := method.attr("", "Algorithm"); algorithm != RSAOAEP {
+		return nil, fmt.Errorf("soap: unsupported key transport %q", algorithm)
+	}
+	if digest := method.child(DSigNamespace, "DigestMethod"); digest != nil {
+		if algorithm, _ := digest.attr("", "Algorithm"); algorithm != DigestSHA1 {
+			return nil, fmt.Errorf("soap: unsupported key transport digest %q", algorithm)
+		}
+	}
+	value, err := cipherValue(encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := key.Decrypt(rand.Reader, value, &rsa.OAEPOptions{Hash: crypto.SHA1})
+	if err != nil {
+		return nil, errDecrypt
+	}
+	return cek, nil
+}
+
+// decryptData replaces the EncryptedData element by its decrypted content
+// or element.
+func decryptData(data *xmlElement, key []byte) error {
+	if data.parent == nil {
+		return errors.New("soap: encrypted data has no parent element to be replaced in")
+	}
+	method := data.child(XMLEncNamespace, "EncryptionMethod")
+	if method == nil {
+		return errors.New("soap: encrypted data has no EncryptionMethod")
+	}
+	if typ, _ := data.attr("", "Type"); typ != encryptedContent && typ != encryptedElement {
+		return fmt.Errorf("soap: unsupported encrypted data type %q", typ)
+	}
+	algorithm, _ := method.attr("", "Algorithm")
+	value, err := cipherValue(data)
+	if err != nil {
+		return err
+	}
+	plainText, err := decryptBlock(algorithm, key, value)
+	if err != nil {
+		return err
+	}
+
+	// The decrypted nodes are parsed in the scope of the EncryptedData and
+	// take its place in the parent.
+	parent := data.parent
+	if err := data.parseContent(plainText); err != nil {
+		return err
+	}
+	for i, c := range parent.children {
+		if c != data {
+			continue
+		}
+		children := append(append(append([]interface{}{}, parent.children[:i]...), data.children...), parent.children[i+1:]...)
+		parent.children = children
+		break
+	}
+	for _, child := range data.elements() {
+		child.parent = parent
+	}
+	return nil
+}
+
+// cipherValue returns the decoded CipherValue of an encrypted element.
+func cipherValue(e *xmlElement) ([]byte, error) {
+	data := e.child(XMLEncNamespace, "CipherData")
+	if data == nil || data.child(XMLEncNamespace, "CipherValue") == nil {
+		return nil, errors.New("soap: encrypted element has no CipherValue")
+	}
+	value := strings.Join(strings.Fields(data.child(XMLEncNamespace, "CipherValue").text()), "")
+	return base64.StdEncoding.DecodeString(value)
+}
+
+// encryptBlock returns the IV followed by the cipher text of the plain
+// text. CBC uses the XML Encryption padding, whose last byte is the
+// padding length.
+func encryptBlock(algorithm string, key, plainText []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if blockMethods[algorithm].gcm {
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plainText)+aead.Overhead())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+		return aead.Seal(nonce, nonce, plainText, nil), nil
+	}
+
+	padding := aes.BlockSize - len(plainText)%aes.BlockSize
+	padded := make([]byte, len(plainText)+padding)
+	copy(padded, plainText)
+	padded[len(padded)-1] = byte(padding)
+	out := make([]byte, aes.BlockSize+len(padded))
+	if _, err := io.ReadFull(rand.Reader, out[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], padded)
+	return out, nil
+}
+
+// decryptBlock reverses encryptBlock.
+func decryptBlock(algorithm string, key, cipherText []byte) ([]byte, error) {
+	method, ok := blockMethods[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("soap: unsupported encryption algorithm %q", algorithm)
+	}
+	if len(key) != method.keySize {
+		return nil, errDecrypt
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if method.gcm {
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if len(cipherText) < aead.NonceSize() {
+			return nil, errDecrypt
+		}
+		plainText, err := aead.Open(nil, cipherText[:aead.NonceSize()], cipherText[aead.NonceSize():], nil)
+		if err != nil {
+			return nil, errDecrypt
+		}
+		return plainText, nil
+	}
+
+	if len(cipherText) < 2*aes.BlockSize || len(cipherText)%aes.BlockSize != 0 {
+		return nil, errDecrypt
+	}
+	plainText := make([]byte, len(cipherText)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, cipherText[:aes.BlockSize]).CryptBlocks(plainText, cipherText[aes.BlockSize:])
+	padding := int(plainText[len(plainText)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errDecrypt
+	}
+	return plainText[:len(plainText)-padding], nil
+}
@@ -0,0 +1,172 @@
+package soap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequest_Call_Encrypter(t *testing.T) {
+	clientKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, serviceCert := newTestCertificate(t, clientKey), newTestCertificate(t, serviceKey)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		response  *WSSecurity
+		verifier  *Verifier
+	}{
+		{name: "Default algorithm", response: &WSSecurity{Encrypter: &Encrypter{Certificate: clientCert}}},
+		{name: "AES-128-GCM", algorithm: AES128GCM, response: &WSSecurity{Encrypter: &Encrypter{Certificate: clientCert, Algorithm: AES128GCM}}},
+		{name: "AES-128-CBC", algorithm: AES128CBC, response: &WSSecurity{Encrypter: &Encrypter{Certificate: clientCert, Algorithm: AES128CBC}}},
+		{name: "AES-256-CBC", algorithm: AES256CBC, response: &WSSecurity{Encrypter: &Encrypter{Certificate: clientCert, Algorithm: AES256CBC}}},
+		{
+			name:      "Signed then encrypted",
+			algorithm: AES256GCM,
+			response: &WSSecurity{
+				TimestampTTL: time.Minute,
+				Signer:       &Signer{Certificate: serviceCert, PrivateKey: serviceKey},
+				Encrypter:    &Encrypter{Certificate: clientCert},
+			},
+			verifier: &Verifier{Certificates: []*x509.Certificate{serviceCert}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := ioutil.ReadAll(r.Body)
+				received = data
+				root, err := parseXML(data)
+				if err != nil {
+					t.Errorf("parseXML() error = %v", err)
+					return
+				}
+				if err := decrypt(root, serviceKey); err != nil {
+					t.Errorf("decrypt() error = %v", err)
+				}
+				request := &NumberToWords{}
+				if err := unmarshalEnvelope(root.bytes(), request); err != nil || request.UbiNum != "7" {
+					t.Errorf("decrypted request = %v, %v, want UbiNum 7", request, err)
+				}
+
+				response, _ := marshalEnvelope(SOAP11, &NumberToWordsResponse{NumberToWordsResult: "seven"})
+				if response, err = tt.response.secure(response); err != nil {
+					t.Errorf("secure() error = %v", err)
+				}
+				w.Write(response)
+			}))
+			defer server.Close()
+
+			result := &NumberToWordsResponse{}
+			_, err := New().R().
+				SetUrl(server.URL).
+				SetWSSecurity(&WSSecurity{
+					Encrypter:     &Encrypter{Certificate: serviceCert, Algorithm: tt.algorithm},
+					DecryptionKey: clientKey,
+					Verifier:      tt.verifier,
+				}).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				SetPayloadResponse(result).
+				Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+			if bytes.Contains(received, []byte("UbiNum")) {
+				t.Errorf("request body %s is not encrypted", received)
+			}
+			if !strings.Contains(string(received), "<xenc:EncryptedKey") {
+				t.Errorf("request %s has no EncryptedKey", received)
+			}
+			if result.NumberToWordsResult != "seven" {
+				t.Errorf("NumberToWordsResult = %v, want seven", result.NumberToWordsResult)
+			}
+		})
+	}
+}
+
+func TestRequest_Call_DecryptionKey_Errors(t *testing.T) {
+	clientKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, _ := marshalEnvelope(SOAP11, &NumberToWordsResponse{NumberToWordsResult: "seven"})
+	security := &WSSecurity{Encrypter: &Encrypter{Certificate: newTestCertificate(t, clientKey)}}
+	if encrypted, err = security.secure(encrypted); err != nil {
+		t.Fatal(err)
+	}
+
+	// The encrypted Body content as the document root, with the header.
+	header, body := string(encrypted), string(encrypted)
+	header = header[strings.Index(header, "<soap:Header>")+len("<soap:Header>") : strings.Index(header, "</soap:Header>")]
+	body = body[strings.Index(body, "<soap:Body>")+len("<soap:Body>") : strings.Index(body, "</soap:Body>")]
+	root := strings.Replace(body, `Type="`+encryptedContent+`">`, `Type="`+encryptedContent+`" xmlns:soap="`+EnvelopeNamespace+`"><xenc:Header>`+header+`</xenc:Header>`, 1)
+
+	tests := []struct {
+		name     string
+		response []byte
+		key      *rsa.PrivateKey
+		wantErr  error
+	}{
+		{name: "Wrong private key", response: encrypted, key: otherKey, wantErr: ErrDecrypt},
+		{name: "Tampered cipher value", response: bytes.Replace(encrypted, []byte("<xenc:CipherValue>"), []byte("<xenc:CipherValue>AAAA"), 2), key: clientKey, wantErr: ErrDecrypt},
+		{name: "Not XML", response: []byte("Service Unavailable"), key: clientKey, wantErr: ErrDecode},
+		{name: "Encrypted data as the document root", response: []byte(root), key: clientKey, wantErr: ErrDecrypt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(tt.response)
+			}))
+			defer server.Close()
+
+			_, err := New().R().
+				SetUrl(server.URL).
+				SetWSSecurity(&WSSecurity{DecryptionKey: tt.key}).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				SetPayloadResponse(&NumberToWordsResponse{}).
+				Call()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Call() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_encryptBlock(t *testing.T) {
+	plainText := []byte(`<m:Op xmlns:m="urn:m">exactly 32 bytes of content..</m:Op>`)
+	for algorithm, method := range blockMethods {
+		key := make([]byte, method.keySize)
+		rand.Read(key)
+		cipherText, err := encryptBlock(algorithm, key, plainText)
+		if err != nil {
+			t.Fatalf("encryptBlock(%s) error = %v", algorithm, err)
+		}
+		got, err := decryptBlock(algorithm, key, cipherText)
+		if err != nil || !bytes.Equal(got, plainText) {
+			t.Errorf("decryptBlock(%s) = %s, %v, want %s", algorithm, got, err, plainText)
+		}
+		if _, err := decryptBlock(algorithm, key[1:], cipherText); err == nil {
+			t.Errorf("decryptBlock(%s) with a short key, want an error", algorithm)
+		}
+	}
+}
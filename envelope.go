@@ -0,0 +1,247 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	// EnvelopeNamespace is the SOAP 1.1 envelope namespace.
+	EnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+)
+
+var errBodyNotFound = errors.New("soap: envelope has no Body element")
+
+// Envelope struct is the SOAP envelope generated around body-only payloads.
+//
+// Request.Call builds it automatically when the PayloadRequest is not
+// already an envelope, so you only need to declare the operation struct.
+type Envelope struct {
+	XMLName xml.Name `xml:"soap:Envelope"`
+	Xmlns   string   `xml:"xmlns:soap,attr"`
+	Header  *Header  `xml:"soap:Header,omitempty"`
+	Body    Body     `xml:"soap:Body"`
+}
+
+// Header struct holds the SOAP header entries of a generated envelope.
+type Header struct {
+	Content []interface{}
+}
+
+// Body struct holds the operation payload of a generated envelope.
+type Body struct {
+	Content interface{}
+}
+
+// NewEnvelope method wraps the payload in a SOAP 1.1 envelope, set Xmlns
+// to Envelope12Namespace for SOAP 1.2.
+func NewEnvelope(payload interface{}) *Envelope {
+	return &Envelope{
+		Xmlns: EnvelopeNamespace,
+		Body:  Body{Content: payload},
+	}
+}
+
+// AddHeader method appends a header entry to the envelope.
+func (e *Envelope) AddHeader(entry interface{}) *Envelope {
+	if e.Header == nil {
+		e.Header = &Header{}
+	}
+	e.Header.Content = append(e.Header.Content, entry)
+	return e
+}
+
+// isEnvelope reports whether v is declared as a full SOAP envelope,
+// in which case it is sent and decoded as is.
+func isEnvelope(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if _, ok := v.(*Envelope); ok {
+		return true
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	f, ok := t.FieldByName("XMLName")
+	if !ok {
+		return t.Name() == "Envelope"
+	}
+	return localName(f.Tag.Get("xml")) == "Envelope"
+}
+
+// localName returns the element local name declared by an xml struct tag,
+// dropping the namespace, the prefix and the options.
+func localName(tag string) string {
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.LastIndex(tag, " "); i >= 0 {
+		tag = tag[i+1:]
+	}
+	if i := strings.LastIndex(tag, ":"); i >= 0 {
+		tag = tag[i+1:]
+	}
+	return tag
+}
+
+// marshalEnvelope serializes the payload, wrapping it in an envelope of
+// the SOAP version unless it already is one.
+func marshalEnvelope(version SOAPVersion, payload interface{}) ([]byte, error) {
+	if isEnvelope(payload) {
+		return xml.Marshal(payload)
+	}
+	envelope := NewEnvelope(payload)
+	envelope.Xmlns = version.Namespace()
+	return xml.Marshal(envelope)
+}
+
+// encodeEnvelope writes the envelope of marshalEnvelope to w with an
+// xml.Encoder, without holding it in memory.
+func encodeEnvelope(w io.Writer, version SOAPVersion, payload interface{}) error {
+	if !isEnvelope(payload) {
+		envelope := NewEnvelope(payload)
+		envelope.Xmlns = version.Namespace()
+		payload = envelope
+	}
+	return xml.NewEncoder(w).Encode(payload)
+}
+
+// unmarshalEnvelope decodes the response envelope into v. Envelope types
+// receive the whole document, any other type receives the first Body child.
+func unmarshalEnvelope(data []byte, v interface{}) error {
+	if isEnvelope(v) {
+		return xml.Unmarshal(data, v)
+	}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	start, err := bodyElement(d)
+	if err != nil {
+		return err
+	}
+	if start == nil {
+		return nil
+	}
+	return d.DecodeElement(v, start)
+}
+
+// bodyElement advances the decoder to the first child of the SOAP Body, in
+// the namespace of the envelope, and returns its start element, or nil when
+// the Body is empty.
+func bodyElement(d *xml.Decoder) (*xml.StartElement, error) {
+	depth := 0
+	inBody := false
+	var namespace string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, errBodyNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if inBody {
+				return &t, nil
+			}
+			if depth == 1 {
+				namespace = t.Name.Space
+			}
+			if depth == 2 && t.Name.Space == namespace && t.Name.Local == "Body" {
+				inBody = true
+			}
+		case xml.EndElement:
+			if inBody {
+				return nil, nil
+			}
+			depth--
+		}
+	}
+}
+
+// envelopeName returns the namespace prefix and the namespace of the root
+// element of the marshaled envelope.
+func envelopeName(data []byte) (string, string, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return "", "", err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		prefix := start.Name.Space
+		for _, attr := range start.Attr {
+			if (prefix == "" && attr.Name.Space == "" && attr.Name.Local == "xmlns") ||
+				(prefix != "" && attr.Name.Space == "xmlns" && attr.Name.Local == prefix) {
+				return prefix, attr.Value, nil
+			}
+		}
+		return prefix, "", nil
+	}
+}
+
+// insertHeader inserts the entry at the beginning of the SOAP Header of the
+// marshaled envelope, adding the Header before the Body when it is missing.
+func insertHeader(data, entry []byte) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		offset := d.InputOffset()
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return nil, errBodyNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth != 2 {
+				continue
+			}
+			end := d.InputOffset()
+			name := t.Name.Local
+			if t.Name.Space != "" {
+				name = t.Name.Space + ":" + name
+			}
+			var buf bytes.Buffer
+			switch t.Name.Local {
+			case "Header":
+				if bytes.HasSuffix(data[offset:end], []byte("/>")) {
+					buf.Write(data[:end-2])
+					buf.WriteString(">")
+					buf.Write(entry)
+					buf.WriteString("</" + name + ">")
+				} else {
+					buf.Write(data[:end])
+					buf.Write(entry)
+				}
+				buf.Write(data[end:])
+				return buf.Bytes(), nil
+			case "Body":
+				header := strings.TrimSuffix(name, "Body") + "Header"
+				buf.Write(data[:offset])
+				buf.WriteString("<" + header + ">")
+				buf.Write(entry)
+				buf.WriteString("</" + header + ">")
+				buf.Write(data[offset:])
+				return buf.Bytes(), nil
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
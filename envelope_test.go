@@ -0,0 +1,181 @@
+package soap
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type NumberToWords struct {
+	XMLName xml.Name `xml:"http://www.dataaccess.com/webservicesserver/ NumberToWords"`
+	UbiNum  string   `xml:"ubiNum"`
+}
+
+type NumberToWordsResponse struct {
+	XMLName             xml.Name `xml:"http://www.dataaccess.com/webservicesserver/ NumberToWordsResponse"`
+	NumberToWordsResult string   `xml:"NumberToWordsResult"`
+}
+
+func Test_marshalEnvelope(t *testing.T) {
+	request := DummyRequest{}
+	request.Soapenv = "http://schemas.xmlsoap.org/soap/envelope/"
+	request.Body.Request.String = "Hello World!"
+	legacy, _ := xml.Marshal(&request)
+
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    string
+	}{
+		{
+			name:    "Wrap body-only payload",
+			payload: &NumberToWords{UbiNum: "777"},
+			want: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<NumberToWords xmlns="http://www.dataaccess.com/webservicesserver/"><ubiNum>777</ubiNum></NumberToWords>` +
+				`</soap:Body></soap:Envelope>`,
+		},
+		{
+			name:    "Keep envelope payload",
+			payload: &request,
+			want:    string(legacy),
+		},
+		{
+			name:    "Wrap with header entries",
+			payload: NewEnvelope(&NumberToWords{UbiNum: "1"}).AddHeader(&DummyHeader{Token: "abc"}),
+			want: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Header><Token>abc</Token></soap:Header><soap:Body>` +
+				`<NumberToWords xmlns="http://www.dataaccess.com/webservicesserver/"><ubiNum>1</ubiNum></NumberToWords>` +
+				`</soap:Body></soap:Envelope>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshalEnvelope(SOAP11, tt.payload)
+			if err != nil {
+				t.Fatalf("marshalEnvelope() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("marshalEnvelope() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_unmarshalEnvelope(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+				   <soap:Header/>
+				   <soap:Body>
+					  <m:NumberToWordsResponse xmlns:m="http://www.dataaccess.com/webservicesserver/">
+						 <m:NumberToWordsResult>seven hundred and seventy seven</m:NumberToWordsResult>
+					  </m:NumberToWordsResponse>
+				   </soap:Body>
+				</soap:Envelope>`
+
+	tests := []struct {
+		name    string
+		data    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Unwrap body child",
+			data: response,
+			v:    &NumberToWordsResponse{},
+			want: &NumberToWordsResponse{
+				XMLName:             xml.Name{Space: "http://www.dataaccess.com/webservicesserver/", Local: "NumberToWordsResponse"},
+				NumberToWordsResult: "seven hundred and seventy seven",
+			},
+		},
+		{
+			name: "Empty body",
+			data: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`,
+			v:    &NumberToWordsResponse{},
+			want: &NumberToWordsResponse{},
+		},
+		{
+			name: "Body in another namespace",
+			data: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">` +
+				`<x:Body xmlns:x="urn:other"><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>other</NumberToWordsResult></NumberToWordsResponse></x:Body>` +
+				`<soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body>` +
+				`</soap:Envelope>`,
+			v: &NumberToWordsResponse{},
+			want: &NumberToWordsResponse{
+				XMLName:             xml.Name{Space: "http://www.dataaccess.com/webservicesserver/", Local: "NumberToWordsResponse"},
+				NumberToWordsResult: "seven",
+			},
+		},
+		{
+			name:    "Missing body",
+			data:    `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"></soap:Envelope>`,
+			v:       &NumberToWordsResponse{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unmarshalEnvelope([]byte(tt.data), tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unmarshalEnvelope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("unmarshalEnvelope() = %v, want %v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isEnvelope(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want bool
+	}{
+		{name: "Prefixed envelope", v: &DummyRequest{}, want: true},
+		{name: "Generated envelope", v: NewEnvelope(nil), want: true},
+		{name: "Body-only payload", v: &NumberToWords{}, want: false},
+		{name: "Nil payload", v: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isEnvelope(tt.v); got != tt.want {
+				t.Errorf("isEnvelope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_Call_BodyOnly(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+			`<NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/">` +
+			`<NumberToWordsResult>nine</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	resp, err := New().R().
+		SetUrl(server.URL).
+		SetPayloadRequest(&NumberToWords{UbiNum: "9"}).
+		SetPayloadResponse(&NumberToWordsResponse{}).
+		Call()
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if got := resp.PayloadResult().(*NumberToWordsResponse).NumberToWordsResult; got != "nine" {
+		t.Errorf("Call() result = %v, want %v", got, "nine")
+	}
+	want, _ := marshalEnvelope(SOAP11, &NumberToWords{UbiNum: "9"})
+	if string(received) != string(want) {
+		t.Errorf("Call() sent = %s, want %s", received, want)
+	}
+}
+
+type DummyHeader struct {
+	XMLName xml.Name `xml:"Token"`
+	Token   string   `xml:",chardata"`
+}
@@ -0,0 +1,70 @@
+package soap
+
+import (
+	"errors"
+)
+
+// Sentinel errors matched with errors.Is against the errors returned by Call.
+//
+//	if errors.Is(err, soap.ErrTransport) {
+//		// the service could not be reached
+//	}
+var (
+	// ErrMarshal is returned when the payload request can not be serialized.
+	ErrMarshal = errors.New("soap: marshal request")
+	// ErrRequest is returned when the HTTP request can not be built, e.g. for a malformed URL.
+	ErrRequest = errors.New("soap: build request")
+	// ErrTransport is returned when the HTTP round-trip fails.
+	ErrTransport = errors.New("soap: transport")
+	// ErrRead is returned when the response body can not be read.
+	ErrRead = errors.New("soap: read response")
+	// ErrDecode is returned when the response can not be decoded into the payload response or fault.
+	ErrDecode = errors.New("soap: decode response")
+	// ErrStatus is returned for a non 200 response without a SOAP Fault, but
+	// for a 2xx response without body to a request without payload response.
+	ErrStatus = errors.New("soap: unexpected HTTP status")
+	// ErrSignature is returned when the signature of the response fails the
+	// verification of the WSSecurity Verifier.
+	ErrSignature = errors.New("soap: verify response signature")
+	// ErrDecrypt is returned when the encrypted content of the response can
+	// not be decrypted with the WSSecurity DecryptionKey.
+	ErrDecrypt = errors.New("soap: decrypt response")
+	// ErrCircuitOpen is returned without sending the request while the
+	// circuit of its endpoint is open, see CircuitBreaker.
+	ErrCircuitOpen = errors.New("soap: circuit breaker is open")
+	// ErrFault matches the *Fault errors returned for SOAP Faults.
+	ErrFault = errors.New("soap: fault")
+)
+
+// ErrResponseTooLarge is the cause of the ErrRead error returned when the
+// response exceeds the maximum response size of the request.
+var ErrResponseTooLarge = errors.New("soap: response exceeds the maximum size")
+
+// Error struct wraps the cause of a failed call along with its sentinel Kind.
+// errors.Is matches the Kind, errors.As and errors.Unwrap reach the cause.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func newError(kind, err error) *Error {
+	return &Error{Kind: kind, Err: err}
+}
+
+// Error method returns the kind and the cause of the error.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap method returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is method reports whether target is the Kind of the error.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
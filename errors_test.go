@@ -0,0 +1,110 @@
+package soap
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	cause := io.ErrUnexpectedEOF
+	tests := []struct {
+		name      string
+		err       *Error
+		want      string
+		wantKind  error
+		wantCause error
+	}{
+		{
+			name:      "Error with cause",
+			err:       newError(ErrRead, cause),
+			want:      "soap: read response: unexpected EOF",
+			wantKind:  ErrRead,
+			wantCause: cause,
+		},
+		{
+			name:     "Error without cause",
+			err:      newError(ErrStatus, nil),
+			want:     "soap: unexpected HTTP status",
+			wantKind: ErrStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(tt.err, tt.wantKind) {
+				t.Errorf("errors.Is(%v) = false, want true", tt.wantKind)
+			}
+			if tt.wantCause != nil && !errors.Is(tt.err, tt.wantCause) {
+				t.Errorf("errors.Is(%v) = false, want true", tt.wantCause)
+			}
+			if errors.Is(tt.err, ErrFault) {
+				t.Errorf("errors.Is(%v) = true, want false", ErrFault)
+			}
+		})
+	}
+}
+
+func TestRequest_Call_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fault":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fault11Response))
+		case "/status":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html><body>Bad Gateway</body></html>`))
+		case "/empty":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`))
+		case "/decode":
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>`))
+		}
+	}))
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		url       string
+		payload   interface{}
+		fault     interface{}
+		streaming bool
+		want      error
+	}{
+		{name: "Marshal error", url: server.URL, payload: make(chan int), want: ErrMarshal},
+		{name: "Request error", url: "http://[::1]:namedport", payload: &NumberToWords{}, want: ErrRequest},
+		{name: "Transport error", url: closed.URL, payload: &NumberToWords{}, want: ErrTransport},
+		{name: "Decode error", url: server.URL + "/decode", payload: &NumberToWords{}, want: ErrDecode},
+		{name: "Status error", url: server.URL + "/status", payload: &NumberToWords{}, want: ErrStatus},
+		{name: "Status error with payload fault", url: server.URL + "/status", payload: &NumberToWords{}, fault: &DummyFault{}, want: ErrStatus},
+		{name: "Status error with empty Body", url: server.URL + "/empty", payload: &NumberToWords{}, want: ErrStatus},
+		{name: "Status error with empty Body and payload fault", url: server.URL + "/empty", payload: &NumberToWords{}, fault: &DummyFault{}, want: ErrStatus},
+		{name: "Fault error", url: server.URL + "/fault", payload: &NumberToWords{}, want: ErrFault},
+		{name: "Streamed decode error", url: server.URL + "/decode", payload: &NumberToWords{}, streaming: true, want: ErrDecode},
+		{name: "Streamed status error", url: server.URL + "/status", payload: &NumberToWords{}, streaming: true, want: ErrStatus},
+		{name: "Streamed status error with payload fault", url: server.URL + "/status", payload: &NumberToWords{}, fault: &NumberToWordsResponse{}, streaming: true, want: ErrStatus},
+		{name: "Streamed status error with empty Body", url: server.URL + "/empty", payload: &NumberToWords{}, streaming: true, want: ErrStatus},
+		{name: "Streamed fault error", url: server.URL + "/fault", payload: &NumberToWords{}, streaming: true, want: ErrFault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New().SetStreaming(tt.streaming).R().
+				SetUrl(tt.url).
+				SetPayloadRequest(tt.payload).
+				SetPayloadResponse(&NumberToWordsResponse{})
+			if tt.fault != nil {
+				r.SetPayloadFault(tt.fault)
+			}
+			_, err := r.Call()
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Call() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var errNoDetail = errors.New("soap: fault has no detail entry")
+
+// Fault struct is the error returned by Call when the response Body contains a
+// SOAP Fault. It is filled from the SOAP 1.1 `faultcode/faultstring/faultactor/detail`
+// or the SOAP 1.2 `Code/Reason/Node/Role/Detail` elements.
+//
+//	var fault *soap.Fault
+//	if errors.As(err, &fault) {
+//		fmt.Println(fault.Code, fault.Reason)
+//	}
+type Fault struct {
+	Version SOAPVersion
+	// Code is the fault code as received, e.g. `soap:Server` or `env:Sender`.
+	Code string
+	// Subcodes are the SOAP 1.2 Subcode values, or the dotted suffixes of a
+	// SOAP 1.1 fault code such as `soap:Server.Timeout`.
+	Subcodes []string
+	Reason   string
+	Actor    string
+	Role     string
+	Node     string
+	// Detail is the raw XML content of the fault detail element.
+	Detail []byte
+	// DetailValue is the detail struct registered with Request.SetFaultDetail,
+	// decoded from the first detail entry.
+	DetailValue interface{}
+
+	namespaces map[string]string
+}
+
+// Error method returns the fault code and reason.
+func (f *Fault) Error() string {
+	return fmt.Sprintf("soap: fault %s: %s", f.Code, f.Reason)
+}
+
+// Is method reports whether target is ErrFault.
+func (f *Fault) Is(target error) bool {
+	return target == ErrFault
+}
+
+// CodeLocal method returns the fault code without its namespace prefix,
+// e.g. `Server` for `soap:Server`.
+func (f *Fault) CodeLocal() string {
+	if i := strings.LastIndex(f.Code, ":"); i >= 0 {
+		return f.Code[i+1:]
+	}
+	return f.Code
+}
+
+// DecodeDetail method decodes the first detail entry of the fault into v.
+func (f *Fault) DecodeDetail(v interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteString("<detail")
+	prefixes := make([]string, 0, len(f.namespaces))
+	for prefix := range f.namespaces {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		attr := "xmlns"
+		if prefix != "" {
+			attr += ":" + prefix
+		}
+		fmt.Fprintf(&buf, " %s=%q", attr, f.namespaces[prefix])
+	}
+	buf.WriteString(">")
+	buf.Write(f.Detail)
+	buf.WriteString("</detail>")
+
+	d := xml.NewDecoder(&buf)
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return d.DecodeElement(v, &t)
+		case xml.EndElement:
+			return errNoDetail
+		}
+	}
+}
+
+type faultCode12 struct {
+	Value   string       `xml:"Value"`
+	Subcode *faultCode12 `xml:"Subcode"`
+}
+
+type faultReason12 struct {
+	Text []struct {
+		Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"Text"`
+}
+
+// parseFault looks for a Fault in the SOAP Body and decodes it with the shape
+// of the envelope's SOAP version. It returns nil when the Body has no Fault.
+func parseFault(data []byte) (*Fault, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	scope := []map[string]string{{}}
+	depth := 0
+	inBody := false
+	var namespace string
+	var start xml.StartElement
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, errBodyNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			if inBody {
+				return nil, nil
+			}
+			scope = scope[:len(scope)-1]
+			depth--
+			continue
+		}
+		t, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		scope = append(scope, declaredNamespaces(scope[len(scope)-1], t))
+		depth++
+		if inBody {
+			start = t
+			break
+		}
+		if depth == 1 {
+			namespace = t.Name.Space
+		}
+		if depth == 2 && t.Name.Space == namespace && t.Name.Local == "Body" {
+			inBody = true
+		}
+	}
+	if start.Name.Local != "Fault" {
+		return nil, nil
+	}
+
+	f := &Fault{Version: SOAP11}
+	if version, ok := versionOf(start.Name.Space); ok {
+		f.Version = version
+	}
+	namespaces := scope[len(scope)-1]
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			break
+		}
+		t, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(t.Name.Local) {
+		case "faultcode":
+			err = d.DecodeElement(&f.Code, &t)
+			f.Code = strings.TrimSpace(f.Code)
+			if parts := strings.Split(f.Code, "."); len(parts) > 1 {
+				f.Code, f.Subcodes = parts[0], parts[1:]
+			}
+		case "faultstring":
+			err = d.DecodeElement(&f.Reason, &t)
+		case "faultactor":
+			err = d.DecodeElement(&f.Actor, &t)
+		case "code":
+			code := faultCode12{}
+			err = d.DecodeElement(&code, &t)
+			f.Code = strings.TrimSpace(code.Value)
+			for sub := code.Subcode; sub != nil; sub = sub.Subcode {
+				f.Subcodes = append(f.Subcodes, strings.TrimSpace(sub.Value))
+			}
+		case "reason":
+			reason := faultReason12{}
+			err = d.DecodeElement(&reason, &t)
+			if len(reason.Text) > 0 {
+				f.Reason = reason.Text[0].Value
+			}
+		case "node":
+			err = d.DecodeElement(&f.Node, &t)
+		case "role":
+			err = d.DecodeElement(&f.Role, &t)
+		case "detail":
+			f.namespaces = declaredNamespaces(namespaces, t)
+			f.Detail, err = innerXML(d, data)
+		default:
+			err = d.Skip()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.Reason = strings.TrimSpace(f.Reason)
+	f.Actor = strings.TrimSpace(f.Actor)
+	f.Node = strings.TrimSpace(f.Node)
+	f.Role = strings.TrimSpace(f.Role)
+	return f, nil
+}
+
+// declaredNamespaces returns the namespace prefixes in scope for the element.
+func declaredNamespaces(parent map[string]string, start xml.StartElement) map[string]string {
+	var scope map[string]string
+	for _, attr := range start.Attr {
+		prefix, ok := "", false
+		switch {
+		case attr.Name.Space == "xmlns":
+			prefix, ok = attr.Name.Local, true
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			ok = true
+		}
+		if !ok {
+			continue
+		}
+		if scope == nil {
+			scope = make(map[string]string, len(parent)+1)
+			for k, v := range parent {
+				scope[k] = v
+			}
+		}
+		scope[prefix] = attr.Value
+	}
+	if scope == nil {
+		return parent
+	}
+	return scope
+}
+
+// innerXML returns the raw content of the element whose start tag was just
+// read from the decoder, consuming it up to its end tag.
+func innerXML(d *xml.Decoder, data []byte) ([]byte, error) {
+	begin := d.InputOffset()
+	end := begin
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return data[begin:end], nil
+			}
+			depth--
+		}
+		end = d.InputOffset()
+	}
+}
+
+// fault11 and fault12 are the shapes of the Fault sent by the Server.
+type fault11 struct {
+	XMLName xml.Name     `xml:"soap:Fault"`
+	Code    string       `xml:"faultcode"`
+	Reason  string       `xml:"faultstring"`
+	Actor   string       `xml:"faultactor,omitempty"`
+	Detail  *faultDetail `xml:"detail,omitempty"`
+}
+
+type fault12 struct {
+	XMLName xml.Name     `xml:"soap:Fault"`
+	Code    faultValue12 `xml:"soap:Code"`
+	Reason  faultText12  `xml:"soap:Reason>soap:Text"`
+	Node    string       `xml:"soap:Node,omitempty"`
+	Role    string       `xml:"soap:Role,omitempty"`
+	Detail  *faultDetail `xml:"soap:Detail,omitempty"`
+}
+
+type faultValue12 struct {
+	Value   string        `xml:"soap:Value"`
+	Subcode *faultValue12 `xml:"soap:Subcode,omitempty"`
+}
+
+type faultText12 struct {
+	Lang  string `xml:"xml:lang,attr"`
+	Value string `xml:",chardata"`
+}
+
+type faultDetail struct {
+	Content interface{}
+	Raw     []byte `xml:",innerxml"`
+}
+
+// faultCodes are the standard fault codes of SOAP 1.1 and SOAP 1.2, mapped
+// to their SOAP 1.1 and SOAP 1.2 equivalents.
+var faultCodes = map[string]struct{ soap11, soap12 string }{
+	"VersionMismatch":     {"VersionMismatch", "VersionMismatch"},
+	"MustUnderstand":      {"MustUnderstand", "MustUnderstand"},
+	"DataEncodingUnknown": {"Client", "DataEncodingUnknown"},
+	"Client":              {"Client", "Sender"},
+	"Sender":              {"Client", "Sender"},
+	"Server":              {"Server", "Receiver"},
+	"Receiver":            {"Server", "Receiver"},
+}
+
+// envelopeCode returns the fault code in the envelope of the SOAP version.
+// The standard codes are translated between versions and prefixed with the
+// envelope prefix, any other code is kept as is. An empty code is a server
+// fault.
+func (f *Fault) envelopeCode(version SOAPVersion) string {
+	local := f.CodeLocal()
+	if f.Code == "" {
+		local = "Server"
+	}
+	codes, ok := faultCodes[local]
+	if !ok {
+		return f.Code
+	}
+	if version == SOAP12 {
+		return "soap:" + codes.soap12
+	}
+	return "soap:" + codes.soap11
+}
+
+// marshalFault returns the envelope of the fault in the SOAP version.
+// DetailValue is encoded as the detail entry, Detail is used when it is nil.
+func marshalFault(version SOAPVersion, f *Fault) ([]byte, error) {
+	var detail *faultDetail
+	if f.DetailValue != nil {
+		detail = &faultDetail{Content: f.DetailValue}
+	} else if len(f.Detail) > 0 {
+		detail = &faultDetail{Raw: f.Detail}
+	}
+
+	var body interface{}
+	if version == SOAP12 {
+		fault := &fault12{
+			Code:   faultValue12{Value: f.envelopeCode(version)},
+			Reason: faultText12{Lang: "en", Value: f.Reason},
+			Node:   f.Node,
+			Role:   f.Role,
+			Detail: detail,
+		}
+		code := &fault.Code
+		for _, sub := range f.Subcodes {
+			code.Subcode = &faultValue12{Value: sub}
+			code = code.Subcode
+		}
+		body = fault
+	} else {
+		code := f.envelopeCode(version)
+		if len(f.Subcodes) > 0 {
+			code += "." + strings.Join(f.Subcodes, ".")
+		}
+		body = &fault11{Code: code, Reason: f.Reason, Actor: f.Actor, Detail: detail}
+	}
+	return marshalEnvelope(version, body)
+}
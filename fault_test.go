@@ -0,0 +1,207 @@
+package soap
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type InvalidNumber struct {
+	XMLName xml.Name `xml:"http://www.dataaccess.com/webservicesserver/ InvalidNumber"`
+	Number  string   `xml:"Number"`
+}
+
+const fault11Response = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:m="http://www.dataaccess.com/webservicesserver/">
+	<soap:Body>
+		<soap:Fault>
+			<faultcode>soap:Client.Validation</faultcode>
+			<faultstring>Invalid number</faultstring>
+			<faultactor>http://www.dataaccess.com/</faultactor>
+			<detail><m:InvalidNumber><m:Number>-1</m:Number></m:InvalidNumber></detail>
+		</soap:Fault>
+	</soap:Body>
+</soap:Envelope>`
+
+const fault12Response = `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+	<env:Body>
+		<env:Fault>
+			<env:Code>
+				<env:Value>env:Sender</env:Value>
+				<env:Subcode><env:Value>m:InvalidNumber</env:Value></env:Subcode>
+			</env:Code>
+			<env:Reason><env:Text xml:lang="en">Invalid number</env:Text></env:Reason>
+			<env:Node>http://www.dataaccess.com/node</env:Node>
+			<env:Role>http://www.dataaccess.com/role</env:Role>
+			<env:Detail><InvalidNumber xmlns="http://www.dataaccess.com/webservicesserver/"><Number>-1</Number></InvalidNumber></env:Detail>
+		</env:Fault>
+	</env:Body>
+</env:Envelope>`
+
+func Test_parseFault(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *Fault
+		wantErr bool
+	}{
+		{
+			name: "SOAP 1.1 fault",
+			data: fault11Response,
+			want: &Fault{
+				Version:  SOAP11,
+				Code:     "soap:Client",
+				Subcodes: []string{"Validation"},
+				Reason:   "Invalid number",
+				Actor:    "http://www.dataaccess.com/",
+				Detail:   []byte(`<m:InvalidNumber><m:Number>-1</m:Number></m:InvalidNumber>`),
+			},
+		},
+		{
+			name: "SOAP 1.2 fault",
+			data: fault12Response,
+			want: &Fault{
+				Version:  SOAP12,
+				Code:     "env:Sender",
+				Subcodes: []string{"m:InvalidNumber"},
+				Reason:   "Invalid number",
+				Node:     "http://www.dataaccess.com/node",
+				Role:     "http://www.dataaccess.com/role",
+				Detail:   []byte(`<InvalidNumber xmlns="http://www.dataaccess.com/webservicesserver/"><Number>-1</Number></InvalidNumber>`),
+			},
+		},
+		{
+			name: "SOAP 1.1 fault with code and reason only",
+			data: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>` +
+				`<faultcode>soap:Server</faultcode><faultstring>Error processing request</faultstring>` +
+				`</soap:Fault></soap:Body></soap:Envelope>`,
+			want: &Fault{
+				Version: SOAP11,
+				Code:    "soap:Server",
+				Reason:  "Error processing request",
+			},
+		},
+		{
+			name: "SOAP 1.2 fault with code and reason only",
+			data: `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+				`<env:Code><env:Value>env:Sender</env:Value></env:Code>` +
+				`<env:Reason><env:Text xml:lang="en">Invalid number</env:Text></env:Reason>` +
+				`</env:Fault></env:Body></env:Envelope>`,
+			want: &Fault{
+				Version: SOAP12,
+				Code:    "env:Sender",
+				Reason:  "Invalid number",
+			},
+		},
+		{
+			name: "Fault in a Body of another namespace",
+			data: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">` +
+				`<x:Body xmlns:x="urn:other"><soap:Fault><faultcode>soap:Server</faultcode><faultstring>other</faultstring></soap:Fault></x:Body>` +
+				`<soap:Body><Response/></soap:Body></soap:Envelope>`,
+			want: nil,
+		},
+		{
+			name: "No fault",
+			data: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Response/></soap:Body></soap:Envelope>`,
+			want: nil,
+		},
+		{
+			name:    "Not an envelope",
+			data:    `<html></html>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFault([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFault() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != nil {
+				got.namespaces = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFault() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFault_DecodeDetail(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "SOAP 1.1 prefixed detail", data: fault11Response},
+		{name: "SOAP 1.2 default namespace detail", data: fault12Response},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fault, err := parseFault([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseFault() error = %v", err)
+			}
+			detail := InvalidNumber{}
+			if err := fault.DecodeDetail(&detail); err != nil {
+				t.Fatalf("DecodeDetail() error = %v", err)
+			}
+			if detail.Number != "-1" {
+				t.Errorf("DecodeDetail() = %v, want %v", detail.Number, "-1")
+			}
+		})
+	}
+}
+
+func TestFault_CodeLocal(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "Prefixed code", code: "soap:Server", want: "Server"},
+		{name: "Unprefixed code", code: "Receiver", want: "Receiver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Fault{Code: tt.code}).CodeLocal(); got != tt.want {
+				t.Errorf("CodeLocal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_Call_Fault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fault11Response))
+	}))
+	defer server.Close()
+
+	resp, err := New().R().
+		SetUrl(server.URL).
+		SetPayloadRequest(&NumberToWords{UbiNum: "-1"}).
+		SetPayloadResponse(&NumberToWordsResponse{}).
+		SetPayloadFault(&DummyFault{}).
+		SetFaultDetail(&InvalidNumber{}).
+		Call()
+
+	var fault *Fault
+	if !errors.As(err, &fault) {
+		t.Fatalf("Call() error = %v, want *Fault", err)
+	}
+	if fault.Code != "soap:Client" || fault.Reason != "Invalid number" {
+		t.Errorf("Call() fault = %v", fault)
+	}
+	if detail, ok := fault.DetailValue.(*InvalidNumber); !ok || detail.Number != "-1" {
+		t.Errorf("Call() fault detail = %v", fault.DetailValue)
+	}
+	if resp.Fault() != fault {
+		t.Errorf("Response.Fault() = %v, want %v", resp.Fault(), fault)
+	}
+	if got := resp.PayloadResultError().(*DummyFault).Body.Fault.Faultstring; got != "Invalid number" {
+		t.Errorf("PayloadResultError() = %v, want %v", got, "Invalid number")
+	}
+}
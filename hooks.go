@@ -0,0 +1,52 @@
+package soap
+
+import (
+	"net/http"
+)
+
+// BeforeSendHook is called before each attempt of a request with the HTTP
+// request, whose headers and URL it can change, and the serialized envelope,
+// or the multipart body, it returns to be sent instead. An error fails the
+// call with that error.
+type BeforeSendHook func(r *Request, req *http.Request, envelope []byte) ([]byte, error)
+
+// AfterReceiveHook is called after each attempt of a request with the
+// response, whose RawResponse headers it can change, and the body as it was
+// received, it returns to be decoded instead. An error fails the call with
+// that error.
+type AfterReceiveHook func(resp *Response, body []byte) ([]byte, error)
+
+// ErrorHook is called once when a call fails, after its last attempt, with
+// the response when one was received. It returns the error of the call,
+// err or another one, nil hiding the failure.
+type ErrorHook func(r *Request, resp *Response, err error) error
+
+// beforeSendHooks returns the before-send hooks of the client followed by
+// the hooks of the request.
+func (r *Request) beforeSendHooks() []BeforeSendHook {
+	var hooks []BeforeSendHook
+	if r.client != nil {
+		hooks = append(hooks, r.client.beforeSend...)
+	}
+	return append(hooks, r.beforeSend...)
+}
+
+// afterReceiveHooks returns the after-receive hooks of the client followed
+// by the hooks of the request.
+func (r *Request) afterReceiveHooks() []AfterReceiveHook {
+	var hooks []AfterReceiveHook
+	if r.client != nil {
+		hooks = append(hooks, r.client.afterReceive...)
+	}
+	return append(hooks, r.afterReceive...)
+}
+
+// errorHooks returns the error hooks of the client followed by the hooks of
+// the request.
+func (r *Request) errorHooks() []ErrorHook {
+	var hooks []ErrorHook
+	if r.client != nil {
+		hooks = append(hooks, r.client.onError...)
+	}
+	return append(hooks, r.onError...)
+}
@@ -0,0 +1,126 @@
+package soap
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequest_Call_Hooks(t *testing.T) {
+	var received []byte
+	var header http.Header
+	var contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		header, contentLength = r.Header, r.ContentLength
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	client := New().SetStreaming(true).
+		OnBeforeSend(func(r *Request, req *http.Request, envelope []byte) ([]byte, error) {
+			calls = append(calls, "client before send")
+			req.Header.Set("X-Signature", "signed")
+			return bytes.Replace(envelope, []byte("secret"), []byte("*****"), 1), nil
+		}).
+		OnAfterReceive(func(resp *Response, body []byte) ([]byte, error) {
+			calls = append(calls, "client after receive")
+			resp.RawResponse.Header.Set("X-Audited", "true")
+			return bytes.Replace(body, []byte("seven"), []byte("SEVEN"), 1), nil
+		}).
+		OnError(func(r *Request, resp *Response, err error) error {
+			calls = append(calls, "client error")
+			return err
+		})
+
+	t.Run("Success", func(t *testing.T) {
+		calls = nil
+		result := &NumberToWordsResponse{}
+		resp, err := client.R().
+			SetUrl(server.URL).
+			SetPayloadRequest(&NumberToWords{UbiNum: "secret"}).
+			SetPayloadResponse(result).
+			OnBeforeSend(func(r *Request, req *http.Request, envelope []byte) ([]byte, error) {
+				calls = append(calls, "request before send")
+				if !bytes.Contains(envelope, []byte("*****")) {
+					t.Errorf("envelope = %s, want the client hook changes", envelope)
+				}
+				return envelope, nil
+			}).
+			OnAfterReceive(func(resp *Response, body []byte) ([]byte, error) {
+				calls = append(calls, "request after receive")
+				return body, nil
+			}).
+			Call()
+		if err != nil {
+			t.Fatalf("Call() error = %v", err)
+		}
+		if want := "client before send,request before send,client after receive,request after receive"; strings.Join(calls, ",") != want {
+			t.Errorf("hooks = %v, want %v", calls, want)
+		}
+		if !strings.Contains(string(received), "<ubiNum>*****</ubiNum>") || contentLength != int64(len(received)) {
+			t.Errorf("request = %s of length %d, want the changed envelope", received, contentLength)
+		}
+		if header.Get("X-Signature") != "signed" {
+			t.Errorf("X-Signature = %v, want signed", header.Get("X-Signature"))
+		}
+		if result.NumberToWordsResult != "SEVEN" || resp.RawResponse.Header.Get("X-Audited") != "true" {
+			t.Errorf("NumberToWordsResult = %v, X-Audited = %v, want the changed response", result.NumberToWordsResult, resp.RawResponse.Header.Get("X-Audited"))
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		calls = nil
+		wrapped := errors.New("wrapped")
+		_, err := client.R().
+			SetUrl(server.URL + "/fail").
+			SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+			OnError(func(r *Request, resp *Response, err error) error {
+				calls = append(calls, "request error")
+				if !errors.Is(err, ErrStatus) || resp.StatusCode() != http.StatusBadGateway {
+					t.Errorf("hook error = %v, status = %v, want the failure", err, resp.StatusCode())
+				}
+				return wrapped
+			}).
+			Call()
+		if err != wrapped {
+			t.Errorf("Call() error = %v, want %v", err, wrapped)
+		}
+		if want := "client before send,client after receive,client error,request error"; strings.Join(calls, ",") != want {
+			t.Errorf("hooks = %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("Hook failure", func(t *testing.T) {
+		denied := errors.New("denied")
+		tests := []struct {
+			name    string
+			request *Request
+		}{
+			{name: "Before send", request: New().R().OnBeforeSend(func(r *Request, req *http.Request, envelope []byte) ([]byte, error) {
+				return nil, denied
+			})},
+			{name: "After receive", request: New().R().OnAfterReceive(func(resp *Response, body []byte) ([]byte, error) {
+				return nil, denied
+			})},
+		}
+		for _, tt := range tests {
+			_, err := tt.request.
+				SetUrl(server.URL).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				Call()
+			if err != denied {
+				t.Errorf("%s: Call() error = %v, want %v", tt.name, err, denied)
+			}
+		}
+	})
+}
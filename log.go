@@ -0,0 +1,206 @@
+package soap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// DefaultLogBodySize is the number of bytes of a logged envelope kept
+// without SetLogMaxBodySize.
+const DefaultLogBodySize = 4096
+
+// DefaultRedactedElements are the elements masked in the logged envelopes
+// without SetLogRedaction, `wsse:Password` included.
+var DefaultRedactedElements = []string{"Password"}
+
+// redactedValue replaces the content of the redacted elements.
+const redactedValue = "[REDACTED]"
+
+// levelDebug is the value of slog.LevelDebug.
+const levelDebug = -4
+
+// Logger interface is the structured logger of the requests raised from a
+// client, see Client.SetLogger. It is satisfied by the *slog.Logger of Go
+// 1.21, the attributes being given as key and value pairs.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// debugEnabled reports whether the logger logs at debug level, asking the
+// Enabled method of a *slog.Logger, so that the envelopes are not redacted
+// for nothing. A logger without such method is always enabled.
+func debugEnabled(ctx context.Context, logger Logger) bool {
+	enabled := reflect.ValueOf(logger).MethodByName("Enabled")
+	if !enabled.IsValid() {
+		return true
+	}
+	t := enabled.Type()
+	if t.NumIn() != 2 || t.In(0) != contextType || t.In(1).Kind() != reflect.Int ||
+		t.NumOut() != 1 || t.Out(0).Kind() != reflect.Bool {
+		return true
+	}
+	return enabled.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(levelDebug).Convert(t.In(1))})[0].Bool()
+}
+
+// logRequest logs the body sent at debug level.
+func (r *Request) logRequest(req *bodyLog) {
+	logger := r.logger()
+	if logger == nil || !debugEnabled(r.Context(), logger) {
+		return
+	}
+	attrs := []interface{}{
+		"operation", r.Operation(),
+		"url", r.Url,
+	}
+	if r.SOAPAction != "" {
+		attrs = append(attrs, "soap_action", r.SOAPAction)
+	}
+	attrs = append(attrs, req.attrs(r.client)...)
+	logger.DebugContext(r.Context(), "soap request", attrs...)
+}
+
+// logResponse logs the received body at debug level.
+func (r *Request) logResponse(response *Response, body *bodyLog) {
+	logger := r.logger()
+	if logger == nil || !debugEnabled(r.Context(), logger) {
+		return
+	}
+	attrs := []interface{}{
+		"operation", r.Operation(),
+		"status", response.StatusCode(),
+		"latency", response.ReceivedAt().Sub(r.Time),
+	}
+	attrs = append(attrs, body.attrs(r.client)...)
+	logger.DebugContext(r.Context(), "soap response", attrs...)
+}
+
+// logError logs the failure of the call at warning level.
+func (r *Request) logError(response *Response, err error) {
+	logger := r.logger()
+	if logger == nil || err == nil {
+		return
+	}
+	attrs := []interface{}{
+		"operation", r.Operation(),
+		"url", r.Url,
+	}
+	if response != nil {
+		attrs = append(attrs, "status", response.StatusCode())
+	}
+	attrs = append(attrs, "error", err.Error())
+	logger.WarnContext(r.Context(), "soap call failed", attrs...)
+}
+
+func (r *Request) logger() Logger {
+	if r.client == nil {
+		return nil
+	}
+	return r.client.logger
+}
+
+// bodyLog is a request or response body to log, nil when it was streamed.
+type bodyLog struct {
+	contentType string
+	data        []byte
+}
+
+// attrs returns the attributes of the logged body: its envelope, the root
+// part of a multipart body, redacted and truncated.
+func (b *bodyLog) attrs(c *Client) []interface{} {
+	if b == nil {
+		return []interface{}{"streamed", true}
+	}
+	envelope, parts, err := readMultipart(b.contentType, b.data)
+	if err != nil {
+		envelope, parts = b.data, nil
+	}
+	attrs := []interface{}{"size", len(b.data)}
+	if len(parts) > 0 {
+		attrs = append(attrs, "parts", len(parts))
+	}
+	redacted := DefaultRedactedElements
+	if c.redacted != nil {
+		redacted = c.redacted
+	}
+	max := DefaultLogBodySize
+	if c.logBodySize != 0 {
+		max = c.logBodySize
+	}
+	return append(attrs, "envelope", truncate(redact(envelope, redacted), max))
+}
+
+// redact masks the content of the elements of the document with one of the
+// names, a local name or a prefixed name such as `wsse:Password`. The
+// content of such an element left open by a syntax error or by the end of the
+// document is masked to its end.
+func redact(data []byte, names []string) []byte {
+	if len(names) == 0 {
+		return data
+	}
+	var out bytes.Buffer
+	d := xml.NewDecoder(bytes.NewReader(data))
+	written, depth := int64(0), 0
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			if depth > 0 {
+				out.WriteString(redactedValue)
+			} else {
+				out.Write(data[written:])
+			}
+			return out.Bytes()
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				depth++
+			} else if redacts(t.Name, names) {
+				depth = 1
+				out.Write(data[written:d.InputOffset()])
+				written = d.InputOffset()
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				continue
+			}
+			if depth--; depth > 0 {
+				continue
+			}
+			end := d.InputOffset()
+			// A self-closing or empty element has no content to mask.
+			if i := bytes.LastIndex(data[written:end], []byte("</")); i > 0 {
+				out.WriteString(redactedValue)
+				out.Write(data[written+int64(i) : end])
+				written = end
+			}
+		}
+	}
+}
+
+// redacts reports whether the element is one of the names.
+func redacts(name xml.Name, names []string) bool {
+	for _, n := range names {
+		if n == name.Local || (name.Space != "" && n == name.Space+":"+name.Local) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate returns the body as a string of at most max bytes, a negative max
+// keeping it whole.
+func truncate(data []byte, max int) string {
+	if max < 0 || len(data) <= max {
+		return string(data)
+	}
+	cut := data[:max]
+	for len(cut) > 0 && !utf8.Valid(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return string(cut) + "...(" + strconv.Itoa(len(data)) + " bytes)"
+}
@@ -0,0 +1,144 @@
+//go:build go1.21
+// +build go1.21
+
+package soap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_SetLogger(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><CardNumber>4111111111111111</CardNumber><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`
+	fault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>invalid</faultstring></soap:Fault></soap:Body></soap:Envelope>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fault" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fault))
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		url       string
+		level     slog.Level
+		redacted  []string
+		maxSize   int
+		streaming bool
+		want      []string
+		wantNot   []string
+	}{
+		{
+			name:    "Default redaction",
+			url:     server.URL,
+			want:    []string{`"msg":"soap request"`, `"msg":"soap response"`, `#PasswordText">[REDACTED]</wsse:Password>`, `<NumberToWordsResult>seven</NumberToWordsResult>`, `4111111111111111`},
+			wantNot: []string{"secret"},
+		},
+		{
+			name:     "Custom redaction",
+			url:      server.URL,
+			redacted: []string{"wsse:Password", "CardNumber"},
+			want:     []string{`#PasswordText">[REDACTED]</wsse:Password>`, `<CardNumber>[REDACTED]</CardNumber>`},
+			wantNot:  []string{"secret", "4111111111111111"},
+		},
+		{
+			name:    "Truncated",
+			url:     server.URL,
+			maxSize: 64,
+			want:    []string{`"envelope":"<soap:Envelope xmlns:soap=\"http://schemas.xmlsoap.org/soap/envel...(298 bytes)"`},
+			wantNot: []string{"seven"},
+		},
+		{
+			name:      "Streamed",
+			url:       server.URL,
+			streaming: true,
+			want:      []string{`"msg":"soap response","operation":"NumberToWords","status":200`, `"streamed":true`},
+			wantNot:   []string{"secret", "4111111111111111"},
+		},
+		{
+			name:    "Fault",
+			url:     server.URL + "/fault",
+			want:    []string{`"level":"WARN","msg":"soap call failed"`, `"status":500`, `"error":"soap: fault soap:Client: invalid"`},
+			wantNot: []string{"secret"},
+		},
+		{
+			name:    "Warning level",
+			url:     server.URL + "/fault",
+			level:   slog.LevelWarn,
+			want:    []string{`"msg":"soap call failed"`},
+			wantNot: []string{`"msg":"soap request"`, `"msg":"soap response"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			level := tt.level
+			if level == 0 {
+				level = slog.LevelDebug
+			}
+			client := New().
+				SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))).
+				SetWSSecurity(&WSSecurity{UsernameToken: &UsernameToken{Username: "user", Password: "secret"}}).
+				SetLogMaxBodySize(tt.maxSize).
+				SetStreaming(tt.streaming)
+			if tt.redacted != nil {
+				client.SetLogRedaction(tt.redacted...)
+			}
+			client.R().
+				SetUrl(tt.url).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				SetPayloadResponse(&struct{}{}).
+				Call()
+
+			var logged strings.Builder
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				var record map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("log line %s error = %v", line, err)
+				}
+				logged.WriteString(line)
+				if envelope, ok := record["envelope"].(string); ok {
+					logged.WriteString(envelope)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(logged.String(), want) {
+					t.Errorf("logs = %s, want %s", logged.String(), want)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(logged.String(), wantNot) {
+					t.Errorf("logs = %s, do not want %s", logged.String(), wantNot)
+				}
+			}
+		})
+	}
+}
+
+func Test_debugEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		logger Logger
+		want   bool
+	}{
+		{name: "Debug level", logger: slog.New(slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelDebug})), want: true},
+		{name: "Info level", logger: slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)), want: false},
+		{name: "Without Enabled method", logger: &recordingLogger{}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := debugEnabled(context.Background(), tt.logger); got != tt.want {
+				t.Errorf("debugEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package soap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingLogger is a Logger writing its messages and attributes.
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(append([]interface{}{"DEBUG ", msg}, args...)...))
+}
+
+func (l *recordingLogger) WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(append([]interface{}{"WARN ", msg}, args...)...))
+}
+
+func TestClient_SetLogger_Logger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>invalid</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	New().
+		SetLogger(logger).
+		SetWSSecurity(&WSSecurity{UsernameToken: &UsernameToken{Username: "user", Password: "secret"}}).
+		R().
+		SetUrl(server.URL).
+		SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+		Call()
+
+	logged := strings.Join(logger.lines, "\n")
+	for _, want := range []string{"DEBUG soap request", "DEBUG soap response", "WARN soap call failed", "[REDACTED]", "soap: fault soap:Client: invalid"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("logs = %s, want %s", logged, want)
+		}
+	}
+	if strings.Contains(logged, "secret") {
+		t.Errorf("logs = %s, do not want secret", logged)
+	}
+}
+
+func Test_redact(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		names []string
+		want  string
+	}{
+		{
+			name:  "Local name",
+			data:  `<a><Password>secret</Password><b>kept</b></a>`,
+			names: []string{"Password"},
+			want:  `<a><Password>[REDACTED]</Password><b>kept</b></a>`,
+		},
+		{
+			name:  "Prefixed name",
+			data:  `<a><wsse:Password Type="text">secret</wsse:Password><Password>kept</Password></a>`,
+			names: []string{"wsse:Password"},
+			want:  `<a><wsse:Password Type="text">[REDACTED]</wsse:Password><Password>kept</Password></a>`,
+		},
+		{
+			name:  "Nested content",
+			data:  `<a><Card><Number>4111</Number><Cvv>123</Cvv></Card></a>`,
+			names: []string{"Card"},
+			want:  `<a><Card>[REDACTED]</Card></a>`,
+		},
+		{
+			name:  "Self-closing and empty elements",
+			data:  `<a><Password/><Password></Password></a>`,
+			names: []string{"Password"},
+			want:  `<a><Password/><Password></Password></a>`,
+		},
+		{
+			name:  "Truncated document",
+			data:  `<a><Password>secr`,
+			names: []string{"Password"},
+			want:  `<a><Password>[REDACTED]`,
+		},
+		{
+			name: "No names",
+			data: `<a><Password>secret</Password></a>`,
+			want: `<a><Password>secret</Password></a>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redact([]byte(tt.data), tt.names)); got != tt.want {
+				t.Errorf("redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_truncate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		max  int
+		want string
+	}{
+		{name: "Short", data: "<a/>", max: 10, want: "<a/>"},
+		{name: "Long", data: "<a>text</a>", max: 3, want: "<a>...(11 bytes)"},
+		{name: "Multibyte rune", data: "<a>é</a>", max: 4, want: "<a>...(9 bytes)"},
+		{name: "Whole", data: "<a>text</a>", max: -1, want: "<a>text</a>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate([]byte(tt.data), tt.max); got != tt.want {
+				t.Errorf("truncate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package soap
+
+import (
+	"errors"
+	"time"
+)
+
+// CallMetrics struct holds the measures of an attempt of a call, recorded by
+// the Metrics of the client once its response is decoded or it failed.
+type CallMetrics struct {
+	// Operation is the name of the operation, see Request.Operation.
+	Operation string
+	Endpoint  string
+	Version   SOAPVersion
+	// Attempt is the number of the attempt, from 1.
+	Attempt int
+	// StatusCode is the HTTP status of the response, zero when none was received.
+	StatusCode int
+	// Latency is the time from sending the request to receiving the response
+	// headers, Response.ReceivedAt minus Request.Time, or to the failure when
+	// no response was received.
+	Latency time.Duration
+	// FaultCode is the code of the SOAP Fault of the response, without its
+	// prefix, e.g. `Server`.
+	FaultCode string
+	// Err is the error of the attempt, matched with errors.Is against the
+	// sentinel errors, nil for a success.
+	Err          error
+	RequestSize  int64
+	ResponseSize int64
+}
+
+// TransportError method reports whether the attempt failed to reach the service.
+func (m *CallMetrics) TransportError() bool {
+	return errors.Is(m.Err, ErrTransport)
+}
+
+// Metrics interface records the attempts of the calls of the requests raised
+// from a client, see Client.SetMetrics. Record is called concurrently by the
+// requests.
+type Metrics interface {
+	Record(m *CallMetrics)
+}
+
+// record passes the measures of the attempt to the client Metrics.
+func (r *Request) record(attempt int, resp *Response, err error) {
+	if r.client == nil || r.client.metrics == nil {
+		return
+	}
+	m := &CallMetrics{
+		Operation: r.Operation(),
+		Endpoint:  r.Url,
+		Version:   r.soapVersion(),
+		Attempt:   attempt,
+		Err:       err,
+	}
+	if resp != nil {
+		m.StatusCode = resp.StatusCode()
+		m.Latency = resp.ReceivedAt().Sub(r.Time)
+		m.RequestSize, m.ResponseSize = resp.RequestSize(), resp.Size()
+	} else if !r.Time.IsZero() {
+		m.Latency = time.Since(r.Time)
+	}
+	var fault *Fault
+	if errors.As(err, &fault) {
+		m.FaultCode = fault.CodeLocal()
+	}
+	r.client.metrics.Record(m)
+}
@@ -0,0 +1,92 @@
+package soap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedMetrics struct {
+	mu    sync.Mutex
+	calls []*CallMetrics
+}
+
+func (m *recordedMetrics) Record(call *CallMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func TestClient_SetMetrics(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`
+	fault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>busy</faultstring></soap:Fault></soap:Body></soap:Envelope>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Path == "/fault" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fault))
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		url   string
+		retry *RetryPolicy
+		want  []CallMetrics
+	}{
+		{
+			name: "Success",
+			url:  server.URL,
+			want: []CallMetrics{{Operation: "NumberToWords", Endpoint: server.URL, Version: SOAP11, Attempt: 1, StatusCode: http.StatusOK}},
+		},
+		{
+			name:  "Retried fault",
+			url:   server.URL + "/fault",
+			retry: &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			want: []CallMetrics{
+				{Operation: "NumberToWords", Endpoint: server.URL + "/fault", Version: SOAP11, Attempt: 1, StatusCode: http.StatusInternalServerError, FaultCode: "Server", Err: ErrFault},
+				{Operation: "NumberToWords", Endpoint: server.URL + "/fault", Version: SOAP11, Attempt: 2, StatusCode: http.StatusInternalServerError, FaultCode: "Server", Err: ErrFault},
+			},
+		},
+		{
+			name: "Transport error",
+			url:  "http://127.0.0.1:1",
+			want: []CallMetrics{{Operation: "NumberToWords", Endpoint: "http://127.0.0.1:1", Version: SOAP11, Attempt: 1, Err: ErrTransport}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &recordedMetrics{}
+			New().SetMetrics(metrics).SetRetryPolicy(tt.retry).R().
+				SetUrl(tt.url).
+				SetIdempotent(true).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				Call()
+			if len(metrics.calls) != len(tt.want) {
+				t.Fatalf("recorded %v attempts, want %v", len(metrics.calls), len(tt.want))
+			}
+			for i, got := range metrics.calls {
+				want := tt.want[i]
+				if got.Operation != want.Operation || got.Endpoint != want.Endpoint || got.Version != want.Version ||
+					got.Attempt != want.Attempt || got.StatusCode != want.StatusCode || got.FaultCode != want.FaultCode {
+					t.Errorf("Record() = %+v, want %+v", got, want)
+				}
+				if want.Err == nil && got.Err != nil || !errors.Is(got.Err, want.Err) {
+					t.Errorf("Record() error = %v, want %v", got.Err, want.Err)
+				}
+				if got.TransportError() != (want.Err == ErrTransport) {
+					t.Errorf("TransportError() = %v", got.TransportError())
+				}
+				if want.StatusCode != 0 && (got.Latency < 10*time.Millisecond || got.RequestSize == 0 || got.ResponseSize == 0) {
+					t.Errorf("Record() latency = %v, sizes = %v %v, want the measures", got.Latency, got.RequestSize, got.ResponseSize)
+				}
+			}
+		})
+	}
+}
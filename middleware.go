@@ -0,0 +1,63 @@
+package soap
+
+import (
+	"encoding/xml"
+	"reflect"
+)
+
+// CallFunc executes a request and returns its response, like Request.Call.
+type CallFunc func(r *Request) (*Response, error)
+
+// Middleware wraps the calls of the requests raised from a client, e.g. to
+// trace or to measure them. It calls next to execute the request, with all
+// its attempts, and can change the request before and the result after.
+//
+//	func timing(next soap.CallFunc) soap.CallFunc {
+//		return func(r *soap.Request) (*soap.Response, error) {
+//			start := time.Now()
+//			resp, err := next(r)
+//			log.Printf("%s: %v", r.Operation(), time.Since(start))
+//			return resp, err
+//		}
+//	}
+type Middleware func(next CallFunc) CallFunc
+
+// chain returns the call of the request through the client middlewares, the
+// first one being the outermost.
+func (r *Request) chain() CallFunc {
+	call := CallFunc((*Request).call)
+	if r.client == nil {
+		return call
+	}
+	for i := len(r.client.middlewares) - 1; i >= 0; i-- {
+		call = r.client.middlewares[i](call)
+	}
+	return call
+}
+
+// operationName returns the local name of the payload element, empty for an
+// envelope payload.
+func operationName(payload interface{}) string {
+	if payload == nil || isEnvelope(payload) {
+		return ""
+	}
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if f, ok := v.Type().FieldByName("XMLName"); ok {
+		if name, ok := v.FieldByIndex(f.Index).Interface().(xml.Name); ok && name.Local != "" {
+			return name.Local
+		}
+		if local := localName(f.Tag.Get("xml")); local != "" {
+			return local
+		}
+	}
+	return v.Type().Name()
+}
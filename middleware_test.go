@@ -0,0 +1,135 @@
+package soap
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type contextKey struct{}
+
+func TestClient_Use(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Trace")
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next CallFunc) CallFunc {
+			return func(r *Request) (*Response, error) {
+				calls = append(calls, name+" before")
+				r.SetHeader("X-Trace", r.Header.Get("X-Trace")+name)
+				r.SetContext(context.WithValue(r.Context(), contextKey{}, name))
+				resp, err := next(r)
+				calls = append(calls, name+" after")
+				return resp, err
+			}
+		}
+	}
+	failed := errors.New("failed")
+	client := New().
+		Use(middleware("a")).
+		Use(middleware("b")).
+		OnBeforeSend(func(r *Request, req *http.Request, envelope []byte) ([]byte, error) {
+			calls = append(calls, "send "+req.Context().Value(contextKey{}).(string))
+			return envelope, nil
+		}).
+		Use(func(next CallFunc) CallFunc {
+			return func(r *Request) (*Response, error) {
+				resp, _ := next(r)
+				return resp, failed
+			}
+		}).
+		OnError(func(r *Request, resp *Response, err error) error {
+			calls = append(calls, "error")
+			return err
+		})
+
+	resp, err := client.R().
+		SetUrl(server.URL).
+		SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+		Call()
+	if err != failed || resp == nil || resp.StatusCode() != http.StatusOK {
+		t.Fatalf("Call() = %v, %v, want the response and %v", resp, err, failed)
+	}
+	if want := "a before,b before,send b,b after,a after,error"; strings.Join(calls, ",") != want {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if header != "ab" {
+		t.Errorf("X-Trace = %v, want ab", header)
+	}
+	if resp.RequestSize() == 0 || resp.Size() != int64(len(response)) {
+		t.Errorf("RequestSize() = %v, Size() = %v, want the body sizes", resp.RequestSize(), resp.Size())
+	}
+}
+
+func TestRequest_Operation(t *testing.T) {
+	type Untagged struct {
+		Value string
+	}
+	type Dynamic struct {
+		XMLName xml.Name
+	}
+	request := DummyRequest{}
+	tests := []struct {
+		name    string
+		payload interface{}
+		action  string
+		want    string
+	}{
+		{name: "Tagged payload", payload: &NumberToWords{}, action: "urn:action", want: "NumberToWords"},
+		{name: "Untagged payload", payload: Untagged{}, want: "Untagged"},
+		{name: "Runtime name", payload: &Dynamic{XMLName: xml.Name{Local: "GetQuote"}}, want: "GetQuote"},
+		{name: "Envelope payload", payload: &request, action: "urn:action", want: "urn:action"},
+		{name: "No payload", action: "urn:action", want: "urn:action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New().R().SetPayloadRequest(tt.payload).SetSOAPAction(tt.action)
+			if got := r.Operation(); got != tt.want {
+				t.Errorf("Operation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponse_Size_Streaming(t *testing.T) {
+	rows := strings.Repeat("<Row>row</Row>", 1000)
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Report xmlns="urn:test">` + rows + `</Report></soap:Body></soap:Envelope>`
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 1024)
+		for {
+			n, err := r.Body.Read(buf)
+			received += n
+			if err != nil {
+				break
+			}
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	resp, err := New().SetStreaming(true).R().
+		SetUrl(server.URL).
+		SetPayloadRequest(&NumberToWords{UbiNum: strings.Repeat("7", 10000)}).
+		SetPayloadResponse(&Report{}).
+		Call()
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if resp.RequestSize() != int64(received) {
+		t.Errorf("RequestSize() = %v, want %v", resp.RequestSize(), received)
+	}
+	if resp.Size() < int64(len(rows)) || resp.Size() > int64(len(response)) {
+		t.Errorf("Size() = %v, want the bytes read up to %v", resp.Size(), len(response))
+	}
+}
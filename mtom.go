@@ -0,0 +1,418 @@
+package soap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// XOPNamespace is the namespace of the `xop:Include` element.
+const XOPNamespace = "http://www.w3.org/2004/08/xop/include"
+
+var (
+	bytesType  = reflect.TypeOf([]byte(nil))
+	readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// mimePart is a part of a multipart/related message.
+type mimePart struct {
+	contentID string
+	header    textproto.MIMEHeader
+	data      []byte
+}
+
+// xopPlaceholder stands for an io.Reader field while the payload is
+// marshaled or unmarshaled, the field content being the token of its part.
+type xopPlaceholder struct {
+	token string
+}
+
+func (x *xopPlaceholder) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (x *xopPlaceholder) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(x.token, start)
+}
+
+func (x *xopPlaceholder) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	x.token = strings.TrimSpace(s)
+	return nil
+}
+
+// binaryFields calls fn with the settable []byte and io.Reader fields of the
+// elements reachable from v.
+func binaryFields(v reflect.Value, fn func(field reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			binaryFields(v.Elem(), fn)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			binaryFields(v.Index(i), fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if (f.PkgPath != "" && !f.Anonymous) || !elementField(f.Tag.Get("xml")) {
+				continue
+			}
+			field := v.Field(i)
+			if f.Type == bytesType || f.Type == readerType {
+				if field.CanSet() {
+					fn(field)
+				}
+				continue
+			}
+			binaryFields(field, fn)
+		}
+	}
+}
+
+// elementField reports whether a field with the xml struct tag is marshaled
+// as element content.
+func elementField(tag string) bool {
+	if tag == "-" {
+		return false
+	}
+	options := strings.Split(tag, ",")[1:]
+	for _, option := range options {
+		switch option {
+		case "attr", "innerxml", "comment":
+			return false
+		}
+	}
+	return true
+}
+
+// randomToken returns a random hexadecimal string with the prefix.
+func randomToken(prefix string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// copyValue returns an addressable copy of v, the pointers, slices and
+// exported fields leading to its binary fields being copied too, so that
+// they can be replaced without changing v. The io.Reader fields are shared.
+func copyValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			c.Set(copyValue(v.Elem()).Addr())
+		}
+	case reflect.Interface:
+		if !v.IsNil() && v.Type() != readerType {
+			c.Set(copyValue(v.Elem()))
+		} else {
+			c.Set(v)
+		}
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			c.Set(v)
+			break
+		}
+		c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(copyValue(v.Field(i)))
+			}
+		}
+	default:
+		c.Set(v)
+	}
+	return c
+}
+
+// marshalMTOM serializes the payload like marshalEnvelope, its non empty
+// []byte and io.Reader fields being sent as parts referenced by
+// `xop:Include` elements. The fields are replaced in a copy of the payload,
+// which is left unchanged but for its io.Reader fields read to their end.
+func marshalMTOM(version SOAPVersion, payload interface{}) ([]byte, []*mimePart, error) {
+	token, err := randomToken("xop-")
+	if err != nil {
+		return nil, nil, err
+	}
+	var parts []*mimePart
+	var tokens []string
+
+	value := reflect.ValueOf(payload)
+	if value.IsValid() {
+		value = copyValue(value)
+	}
+	binaryFields(value, func(field reflect.Value) {
+		if err != nil {
+			return
+		}
+		t := token + "-" + strconv.Itoa(len(parts)) + "x"
+		var data []byte
+		if field.Type() == readerType {
+			if field.IsNil() {
+				return
+			}
+			if data, err = ioutil.ReadAll(field.Interface().(io.Reader)); err != nil {
+				return
+			}
+			field.Set(reflect.ValueOf(&xopPlaceholder{token: t}))
+		} else {
+			if field.Len() == 0 {
+				return
+			}
+			data = field.Bytes()
+			field.SetBytes([]byte(t))
+		}
+		parts = append(parts, &mimePart{
+			contentID: strconv.Itoa(len(parts)+1) + "." + token[len("xop-"):] + "@soap",
+			header:    textproto.MIMEHeader{"Content-Type": {"application/octet-stream"}},
+			data:      data,
+		})
+		tokens = append(tokens, t)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if value.IsValid() {
+		payload = value.Interface()
+	}
+
+	data, err := marshalEnvelope(version, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, part := range parts {
+		include := `<xop:Include xmlns:xop="` + XOPNamespace + `" href="cid:` + url.PathEscape(part.contentID) + `"></xop:Include>`
+		data = bytes.Replace(data, []byte(tokens[i]), []byte(include), 1)
+	}
+	return data, parts, nil
+}
+
+// writeMultipart returns the multipart/related body of the envelope and
+// its parts, with its content type. The envelope is sent as an XOP package
+// with MTOM, else as SOAP with Attachments.
+func writeMultipart(version SOAPVersion, action string, envelope []byte, parts []*mimePart, xop bool) ([]byte, string, error) {
+	rootID, err := randomToken("root.")
+	if err != nil {
+		return nil, "", err
+	}
+	rootID += "@soap"
+	rootType := "text/xml"
+	if version == SOAP12 {
+		rootType = "application/soap+xml"
+		if action != "" && xop {
+			rootType = mime.FormatMediaType(rootType, map[string]string{"action": action})
+		}
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	root := textproto.MIMEHeader{}
+	if xop {
+		root.Set("Content-Type", mime.FormatMediaType("application/xop+xml", map[string]string{"charset": "UTF-8", "type": rootType}))
+	} else {
+		root.Set("Content-Type", version.ContentType(action))
+	}
+	root.Set("Content-Transfer-Encoding", "8bit")
+	root.Set("Content-ID", "<"+rootID+">")
+	all := append([]*mimePart{{header: root, data: envelope}}, parts...)
+	for i, part := range all {
+		header := part.header
+		if i > 0 {
+			header = textproto.MIMEHeader{}
+			for k, v := range part.header {
+				header[k] = v
+			}
+			if header.Get("Content-Type") == "" {
+				header.Set("Content-Type", "application/octet-stream")
+			}
+			header.Set("Content-Transfer-Encoding", "binary")
+			header.Set("Content-ID", "<"+part.contentID+">")
+		}
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := pw.Write(part.data); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	params := map[string]string{
+		"type":     rootType,
+		"boundary": w.Boundary(),
+		"start":    "<" + rootID + ">",
+	}
+	if xop {
+		params["type"] = "application/xop+xml"
+		params["start-info"] = rootType
+	}
+	return buf.Bytes(), mime.FormatMediaType("multipart/related", params), nil
+}
+
+// readMultipart splits a multipart/related body into its root part, the
+// envelope, and the other parts. Any other body is returned as is.
+func readMultipart(contentType string, body []byte) ([]byte, []*mimePart, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/related" {
+		return body, nil, nil
+	}
+	r := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	start := strings.Trim(params["start"], "<>")
+	var root []byte
+	var parts []*mimePart
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := ioutil.ReadAll(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		if strings.EqualFold(p.Header.Get("Content-Transfer-Encoding"), "base64") {
+			if data, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), "")); err != nil {
+				return nil, nil, err
+			}
+		}
+		part := &mimePart{contentID: strings.Trim(p.Header.Get("Content-ID"), "<>"), header: p.Header, data: data}
+		if root == nil && (start == "" || part.contentID == start) {
+			root = data
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if root == nil {
+		return nil, nil, errors.New("soap: multipart response has no root part")
+	}
+	return root, parts, nil
+}
+
+// unmarshalParts decodes the envelope like unmarshalEnvelope, resolving
+// its `xop:Include` elements into the []byte and io.Reader fields of v.
+// The io.Reader fields must be reachable from v before decoding.
+func unmarshalParts(data []byte, parts []*mimePart, v interface{}) error {
+	if len(parts) == 0 {
+		return unmarshalEnvelope(data, v)
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return err
+	}
+	token, err := randomToken("xop-")
+	if err != nil {
+		return err
+	}
+	resolved := map[string][]byte{}
+	if err := resolveIncludes(root, parts, token, resolved); err != nil {
+		return err
+	}
+	if len(resolved) == 0 {
+		return unmarshalEnvelope(data, v)
+	}
+
+	var placeholders []reflect.Value
+	binaryFields(reflect.ValueOf(v), func(field reflect.Value) {
+		if field.Type() == readerType && field.IsNil() {
+			field.Set(reflect.ValueOf(&xopPlaceholder{}))
+			placeholders = append(placeholders, field)
+		}
+	})
+	err = unmarshalEnvelope(root.bytes(), v)
+	for _, field := range placeholders {
+		if data, ok := resolved[field.Interface().(*xopPlaceholder).token]; ok {
+			field.Set(reflect.ValueOf(bytes.NewReader(data)))
+		} else {
+			field.Set(reflect.Zero(readerType))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	binaryFields(reflect.ValueOf(v), func(field reflect.Value) {
+		if field.Type() != bytesType {
+			return
+		}
+		if data, ok := resolved[string(bytes.TrimSpace(field.Bytes()))]; ok {
+			field.SetBytes(data)
+		}
+	})
+	return nil
+}
+
+// resolveIncludes replaces the `xop:Include` elements of the subtree by a
+// token of the part they refer to.
+func resolveIncludes(e *xmlElement, parts []*mimePart, token string, resolved map[string][]byte) error {
+	for i, c := range e.children {
+		child, ok := c.(*xmlElement)
+		if !ok {
+			continue
+		}
+		if !child.is(XOPNamespace, "Include") {
+			if err := resolveIncludes(child, parts, token, resolved); err != nil {
+				return err
+			}
+			continue
+		}
+		href, _ := child.attr("", "href")
+		id, err := url.PathUnescape(strings.TrimPrefix(href, "cid:"))
+		if err != nil {
+			return err
+		}
+		part := findPart(parts, id)
+		if part == nil {
+			return fmt.Errorf("soap: xop:Include of unknown part %q", href)
+		}
+		t := token + "-" + strconv.Itoa(len(resolved)) + "x"
+		resolved[t] = part.data
+		e.children[i] = xmlText(t)
+	}
+	return nil
+}
+
+// findPart returns the part with the content id.
+func findPart(parts []*mimePart, id string) *mimePart {
+	for _, part := range parts {
+		if part.contentID == id {
+			return part
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type UploadDocument struct {
+	XMLName xml.Name  `xml:"urn:test UploadDocument"`
+	Name    string    `xml:"Name"`
+	Content []byte    `xml:"Content"`
+	Scan    io.Reader `xml:"Scan,omitempty"`
+	Pages   []Page    `xml:"Page"`
+}
+
+type Page struct {
+	Data []byte `xml:"Data"`
+}
+
+func TestRequest_Call_MTOM(t *testing.T) {
+	binary := []byte("%PDF-1.4\x00\x01\x02 binary <content> & more")
+	scan := []byte{0xff, 0xd8, 0xff, 0xe0, 0x00}
+
+	var contentType string
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := ioutil.ReadAll(r.Body)
+		root, parts, err := readMultipart(contentType, body)
+		if err != nil {
+			t.Errorf("readMultipart() error = %v", err)
+		}
+		received = root
+
+		// Echo the document back as an MTOM response.
+		document := &UploadDocument{}
+		if err := unmarshalParts(root, parts, document); err != nil {
+			t.Errorf("unmarshalParts() error = %v", err)
+		}
+		envelope, parts, err := marshalMTOM(SOAP12, document)
+		if err != nil {
+			t.Errorf("marshalMTOM() error = %v", err)
+		}
+		data, responseType, err := writeMultipart(SOAP12, "", envelope, parts, true)
+		if err != nil {
+			t.Errorf("writeMultipart() error = %v", err)
+		}
+		w.Header().Set("Content-Type", responseType)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		client       *Client
+		mtom         bool
+		content      []byte
+		scan         bool
+		byValue      bool
+		wantIncludes int
+	}{
+		{name: "Request MTOM", client: New(), mtom: true, content: binary, scan: true, wantIncludes: 4},
+		{name: "Client MTOM", client: New().SetMTOM(true), content: binary, scan: true, wantIncludes: 4},
+		{name: "Payload by value", client: New(), mtom: true, content: binary, scan: true, byValue: true, wantIncludes: 4},
+		// Without MTOM, the content is sent as text and io.Reader fields are not supported.
+		{name: "Without MTOM", client: New(), content: []byte("text content"), wantIncludes: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &UploadDocument{
+				Name:    "scan.pdf",
+				Content: tt.content,
+				Pages:   []Page{{Data: []byte("page 1")}, {Data: []byte("page 2")}},
+			}
+			if tt.scan {
+				request.Scan = bytes.NewReader(scan)
+			}
+			var payload interface{} = request
+			if tt.byValue {
+				payload = *request
+			}
+			response := &UploadDocument{}
+			_, err := tt.client.R().
+				SetUrl(server.URL).
+				SetSOAPVersion(SOAP12).
+				SetSOAPAction("urn:upload").
+				SetMTOM(tt.mtom).
+				SetPayloadRequest(payload).
+				SetPayloadResponse(response).
+				Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+
+			if got := strings.Count(string(received), "<xop:Include"); got != tt.wantIncludes {
+				t.Errorf("xop:Include count = %v, want %v in %s", got, tt.wantIncludes, received)
+			}
+			if tt.wantIncludes > 0 && !strings.HasPrefix(contentType, "multipart/related;") {
+				t.Errorf("Content-Type = %v, want multipart/related", contentType)
+			}
+			if !bytes.Equal(request.Content, tt.content) {
+				t.Errorf("request Content = %q, want it unchanged", request.Content)
+			}
+
+			if response.Name != "scan.pdf" || !bytes.Equal(response.Content, tt.content) {
+				t.Errorf("response = %q, %q, want the echoed document", response.Name, response.Content)
+			}
+			if len(response.Pages) != 2 || string(response.Pages[1].Data) != "page 2" {
+				t.Errorf("response Pages = %v, want the echoed pages", response.Pages)
+			}
+			if tt.scan {
+				if response.Scan == nil {
+					t.Fatalf("response Scan = nil, want the scan part")
+				}
+				if got, _ := ioutil.ReadAll(response.Scan); !bytes.Equal(got, scan) {
+					t.Errorf("response Scan = %v, want %v", got, scan)
+				}
+			}
+		})
+	}
+}
+
+func Test_marshalMTOM_SharedPayload(t *testing.T) {
+	request := &UploadDocument{
+		Name:    "scan.pdf",
+		Content: []byte("content"),
+		Pages:   []Page{{Data: []byte("page 1")}, {Data: []byte("page 2")}},
+	}
+	var wg sync.WaitGroup
+	envelopes := make([][]byte, 8)
+	errs := make([]error, len(envelopes))
+	for i := range envelopes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			envelopes[i], _, errs[i] = marshalMTOM(SOAP11, request)
+		}(i)
+	}
+	wg.Wait()
+	for i, envelope := range envelopes {
+		if errs[i] != nil {
+			t.Fatalf("marshalMTOM() error = %v", errs[i])
+		}
+		if got := strings.Count(string(envelope), "<xop:Include"); got != 3 {
+			t.Errorf("xop:Include count = %v, want 3 in %s", got, envelope)
+		}
+	}
+	if string(request.Content) != "content" || string(request.Pages[0].Data) != "page 1" || string(request.Pages[1].Data) != "page 2" {
+		t.Errorf("request = %q, %q, want it unchanged", request.Content, request.Pages)
+	}
+}
+
+func Test_readMultipart(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantRoot    string
+		wantParts   map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Single part body",
+			contentType: "text/xml; charset=utf-8",
+			body:        "<Envelope/>",
+			wantRoot:    "<Envelope/>",
+		},
+		{
+			name:        "Start parameter and base64 part",
+			contentType: `multipart/related; boundary=b; start="<root>"; type="application/xop+xml"`,
+			body: "--b\r\nContent-ID: <1@x>\r\nContent-Transfer-Encoding: base64\r\n\r\nYWJj\r\n" +
+				"--b\r\nContent-ID: <root>\r\n\r\n<Envelope/>\r\n--b--\r\n",
+			wantRoot:  "<Envelope/>",
+			wantParts: map[string]string{"1@x": "abc"},
+		},
+		{
+			name:        "First part is the root",
+			contentType: `multipart/related; boundary=b`,
+			body:        "--b\r\n\r\n<Envelope/>\r\n--b\r\nContent-ID: <2@x>\r\n\r\ndata\r\n--b--\r\n",
+			wantRoot:    "<Envelope/>",
+			wantParts:   map[string]string{"2@x": "data"},
+		},
+		{
+			name:        "Missing root part",
+			contentType: `multipart/related; boundary=b; start="<root>"`,
+			body:        "--b\r\nContent-ID: <1@x>\r\n\r\nabc\r\n--b--\r\n",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, parts, err := readMultipart(tt.contentType, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readMultipart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(root) != tt.wantRoot {
+				t.Errorf("readMultipart() root = %q, want %q", root, tt.wantRoot)
+			}
+			if len(parts) != len(tt.wantParts) {
+				t.Fatalf("readMultipart() parts = %v, want %v", len(parts), len(tt.wantParts))
+			}
+			for _, part := range parts {
+				if want := tt.wantParts[part.contentID]; string(part.data) != want {
+					t.Errorf("part %s = %q, want %q", part.contentID, part.data, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_unmarshalParts_UnknownPart(t *testing.T) {
+	envelope := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+		`<UploadDocument xmlns="urn:test"><Content><xop:Include xmlns:xop="` + XOPNamespace + `" href="cid:missing"/></Content></UploadDocument>` +
+		`</soap:Body></soap:Envelope>`
+	parts := []*mimePart{{contentID: "other", data: []byte("x")}}
+	if err := unmarshalParts([]byte(envelope), parts, &UploadDocument{}); err == nil {
+		t.Errorf("unmarshalParts() error = nil, want an unknown part error")
+	}
+}
@@ -0,0 +1,137 @@
+// Package otelsoap traces the SOAP calls of a soap.Client with OpenTelemetry.
+//
+//	client := soap.New().
+//		Use(otelsoap.Middleware())
+//
+// Each call is a client span named after its operation, recording the
+// endpoint, the SOAP version, the HTTP status, the fault code and the body
+// sizes of its last attempt. The W3C trace context of the span is sent in the
+// request headers.
+package otelsoap
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/mencosk/soap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer.
+const ScopeName = "github.com/mencosk/soap/otelsoap"
+
+// Attribute keys of the span, along with the semantic convention keys
+// `rpc.system`, `rpc.method`, `url.full`, `server.address`,
+// `http.response.status_code`, `http.request.body.size` and
+// `http.response.body.size`.
+const (
+	SOAPActionKey    = attribute.Key("soap.action")
+	SOAPVersionKey   = attribute.Key("soap.version")
+	SOAPFaultCodeKey = attribute.Key("soap.fault.code")
+)
+
+// Option configures the Middleware.
+type Option func(*config)
+
+type config struct {
+	provider    trace.TracerProvider
+	propagators propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the tracer provider of the spans, the global
+// provider by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithPropagators sets the propagators injecting the span context in the
+// request headers, the global propagators by default.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = propagators
+	}
+}
+
+// Middleware returns a soap.Middleware starting a client span for each call
+// of the requests raised from the client.
+func Middleware(opts ...Option) soap.Middleware {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.provider == nil {
+		c.provider = otel.GetTracerProvider()
+	}
+	if c.propagators == nil {
+		c.propagators = otel.GetTextMapPropagator()
+	}
+	tracer := c.provider.Tracer(ScopeName, trace.WithInstrumentationVersion(soap.Version))
+
+	return func(next soap.CallFunc) soap.CallFunc {
+		return func(r *soap.Request) (*soap.Response, error) {
+			operation := r.Operation()
+			name := operation
+			if name == "" {
+				name = "SOAP call"
+			}
+			attrs := []attribute.KeyValue{
+				attribute.String("rpc.system", "soap"),
+				SOAPVersionKey.String(r.Version().String()),
+			}
+			if operation != "" {
+				attrs = append(attrs, attribute.String("rpc.method", operation))
+			}
+			if r.SOAPAction != "" {
+				attrs = append(attrs, SOAPActionKey.String(r.SOAPAction))
+			}
+			attrs = append(attrs, endpoint(r.Url)...)
+			ctx, span := tracer.Start(r.Context(), name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			c.propagators.Inject(ctx, propagation.HeaderCarrier(r.Header))
+			resp, err := next(r.SetContext(ctx))
+
+			if resp != nil && resp.RawResponse != nil {
+				span.SetAttributes(
+					attribute.Int("http.response.status_code", resp.StatusCode()),
+					attribute.Int64("http.request.body.size", resp.RequestSize()),
+					attribute.Int64("http.response.body.size", resp.Size()),
+				)
+			}
+			var fault *soap.Fault
+			if errors.As(err, &fault) {
+				span.SetAttributes(SOAPFaultCodeKey.String(fault.Code))
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return resp, err
+		}
+	}
+}
+
+// endpoint returns the URL attributes of the endpoint, without its
+// credentials.
+func endpoint(rawURL string) []attribute.KeyValue {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return []attribute.KeyValue{attribute.String("url.full", rawURL)}
+	}
+	if u.User != nil {
+		u.User = url.UserPassword("REDACTED", "REDACTED")
+	}
+	return []attribute.KeyValue{
+		attribute.String("url.full", u.String()),
+		attribute.String("server.address", u.Hostname()),
+	}
+}
@@ -0,0 +1,160 @@
+// Package promsoap exports the metrics of the SOAP calls of a soap.Client to
+// Prometheus.
+//
+//	collector := promsoap.NewCollector(promsoap.CollectorOpts{})
+//	prometheus.MustRegister(collector)
+//	client := soap.New().SetMetrics(collector)
+//
+// The metrics are labeled by operation and by endpoint host, so that the
+// latency and the faults of each operation of a partner service are apart.
+package promsoap
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/mencosk/soap"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CollectorOpts struct configures the metrics of a Collector.
+type CollectorOpts struct {
+	// Namespace and Subsystem prefix the metric names, `soap` and `client`
+	// when empty.
+	Namespace string
+	Subsystem string
+	// DurationBuckets are the buckets in seconds of the latency histogram,
+	// prometheus.DefBuckets when nil.
+	DurationBuckets []float64
+	// SizeBuckets are the buckets in bytes of the body size histograms, from
+	// 256 bytes to 4 MiB when nil.
+	SizeBuckets []float64
+	ConstLabels prometheus.Labels
+}
+
+// Collector struct is a prometheus.Collector of the soap.Metrics of a client.
+type Collector struct {
+	requests        *prometheus.CounterVec
+	duration        *prometheus.HistogramVec
+	faults          *prometheus.CounterVec
+	transportErrors *prometheus.CounterVec
+	requestSize     *prometheus.HistogramVec
+	responseSize    *prometheus.HistogramVec
+}
+
+var _ soap.Metrics = (*Collector)(nil)
+
+// NewCollector returns a Collector of the metrics:
+//
+//	soap_client_requests_total{operation, host, code}
+//	soap_client_request_duration_seconds{operation, host}
+//	soap_client_faults_total{operation, host, fault_code}
+//	soap_client_transport_errors_total{operation, host}
+//	soap_client_request_size_bytes{operation, host}
+//	soap_client_response_size_bytes{operation, host}
+//
+// The code label is the HTTP status code, empty when no response was
+// received. Each attempt of a retried call is counted.
+func NewCollector(opts CollectorOpts) *Collector {
+	namespace, subsystem := opts.Namespace, opts.Subsystem
+	if namespace == "" {
+		namespace = "soap"
+	}
+	if subsystem == "" {
+		subsystem = "client"
+	}
+	durationBuckets, sizeBuckets := opts.DurationBuckets, opts.SizeBuckets
+	if durationBuckets == nil {
+		durationBuckets = prometheus.DefBuckets
+	}
+	if sizeBuckets == nil {
+		sizeBuckets = prometheus.ExponentialBuckets(256, 4, 8)
+	}
+	labels := []string{"operation", "host"}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem, ConstLabels: opts.ConstLabels,
+			Name: "requests_total",
+			Help: "Number of SOAP requests sent, by HTTP status code.",
+		}, append(labels, "code")),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: subsystem, ConstLabels: opts.ConstLabels,
+			Name:    "request_duration_seconds",
+			Help:    "Time from sending a SOAP request to receiving its response headers.",
+			Buckets: durationBuckets,
+		}, labels),
+		faults: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem, ConstLabels: opts.ConstLabels,
+			Name: "faults_total",
+			Help: "Number of SOAP Faults received, by fault code.",
+		}, append(labels, "fault_code")),
+		transportErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem, ConstLabels: opts.ConstLabels,
+			Name: "transport_errors_total",
+			Help: "Number of SOAP requests that failed to reach the service.",
+		}, labels),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: subsystem, ConstLabels: opts.ConstLabels,
+			Name:    "request_size_bytes",
+			Help:    "Size of the SOAP request bodies sent.",
+			Buckets: sizeBuckets,
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: subsystem, ConstLabels: opts.ConstLabels,
+			Name:    "response_size_bytes",
+			Help:    "Size of the SOAP response bodies received.",
+			Buckets: sizeBuckets,
+		}, labels),
+	}
+}
+
+// Record method records the measures of an attempt, see soap.Metrics.
+func (c *Collector) Record(m *soap.CallMetrics) {
+	operation, host := m.Operation, hostOf(m.Endpoint)
+	code := ""
+	if m.StatusCode != 0 {
+		code = strconv.Itoa(m.StatusCode)
+	}
+	c.requests.WithLabelValues(operation, host, code).Inc()
+	if m.TransportError() {
+		c.transportErrors.WithLabelValues(operation, host).Inc()
+	}
+	if m.FaultCode != "" {
+		c.faults.WithLabelValues(operation, host, m.FaultCode).Inc()
+	}
+	if m.StatusCode == 0 {
+		return
+	}
+	c.duration.WithLabelValues(operation, host).Observe(m.Latency.Seconds())
+	c.requestSize.WithLabelValues(operation, host).Observe(float64(m.RequestSize))
+	c.responseSize.WithLabelValues(operation, host).Observe(float64(m.ResponseSize))
+}
+
+// Describe method sends the descriptors of the metrics, see prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.faults.Describe(ch)
+	c.transportErrors.Describe(ch)
+	c.requestSize.Describe(ch)
+	c.responseSize.Describe(ch)
+}
+
+// Collect method sends the metrics, see prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.faults.Collect(ch)
+	c.transportErrors.Collect(ch)
+	c.requestSize.Collect(ch)
+	c.responseSize.Collect(ch)
+}
+
+// hostOf returns the host of the endpoint URL, with its port.
+func hostOf(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
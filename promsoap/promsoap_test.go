@@ -0,0 +1,84 @@
+package promsoap
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mencosk/soap"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type NumberToWords struct {
+	XMLName xml.Name `xml:"http://www.dataaccess.com/webservicesserver/ NumberToWords"`
+	UbiNum  string   `xml:"ubiNum"`
+}
+
+func TestCollector(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`
+	fault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>invalid</faultstring></soap:Fault></soap:Body></soap:Envelope>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fault" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fault))
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	collector := NewCollector(CollectorOpts{ConstLabels: prometheus.Labels{"partner": "numbers"}})
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	client := soap.New().SetMetrics(collector)
+	for _, url := range []string{server.URL, server.URL, server.URL + "/fault", "http://127.0.0.1:1"} {
+		client.R().
+			SetUrl(url).
+			SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+			Call()
+	}
+
+	tests := []struct {
+		name      string
+		collector prometheus.Collector
+		want      float64
+	}{
+		{name: "Successful requests", collector: collector.requests.WithLabelValues("NumberToWords", host, "200"), want: 2},
+		{name: "Failed requests", collector: collector.requests.WithLabelValues("NumberToWords", host, "500"), want: 1},
+		{name: "Unanswered requests", collector: collector.requests.WithLabelValues("NumberToWords", "127.0.0.1:1", ""), want: 1},
+		{name: "Faults", collector: collector.faults.WithLabelValues("NumberToWords", host, "Client"), want: 1},
+		{name: "Transport errors", collector: collector.transportErrors.WithLabelValues("NumberToWords", "127.0.0.1:1"), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testutil.ToFloat64(tt.collector); got != tt.want {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if problems, err := testutil.GatherAndLint(registry); err != nil || len(problems) > 0 {
+		t.Errorf("GatherAndLint() = %v, %v", problems, err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	counts := map[string]uint64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				counts[family.GetName()] += h.GetSampleCount()
+			}
+		}
+	}
+	for _, name := range []string{"soap_client_request_duration_seconds", "soap_client_request_size_bytes", "soap_client_response_size_bytes"} {
+		if counts[name] != 3 {
+			t.Errorf("%s count = %v, want 3", name, counts[name])
+		}
+	}
+}
@@ -2,22 +2,41 @@ package soap
 
 import (
 	"bytes"
-	"encoding/xml"
+	"context"
+	"errors"
+	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"reflect"
+	"sync/atomic"
 	"time"
 )
 
 type Request struct {
 	Url             string
 	Header          http.Header
+	SOAPAction      string
+	SOAPVersion     SOAPVersion
 	PayloadRequest  interface{}
 	PayloadResponse interface{}
 	PayloadFault    interface{}
+	FaultDetail     interface{}
+	WSSecurity      *WSSecurity
+	Addressing      *Addressing
+	MTOM            bool
+	Attachments     []*Attachment
+	Streaming       bool
+	MaxResponseSize int64
+	DeferDecoding   bool
+	RetryPolicy     *RetryPolicy
+	Idempotent      bool
 	RawRequest      *http.Request
 	client          *Client
+	beforeSend      []BeforeSendHook
+	afterReceive    []AfterReceiveHook
+	onError         []ErrorHook
+	ctx             context.Context
+	messageID       string
 	Time            time.Time
 }
 
@@ -27,6 +46,11 @@ type Request struct {
 // 		client.R().
 //			SetUrl("http://mywebservice.com/km/add")
 //
+// A url without scheme, a path or a query such as `?op=Add`, is resolved against the base URL set at
+// client instance level when the request is called.
+// 		client.SetBaseURL("http://mywebservice.com/km").R().
+//			SetUrl("add")
+//
 func (r *Request) SetUrl(url string) *Request {
 	r.Url = url
 	return r
@@ -46,6 +70,215 @@ func (r *Request) SetHeader(header, value string) *Request {
 	return r
 }
 
+// SetSOAPAction method is to set the SOAP action of the operation called by the current request.
+//
+// For SOAP 1.1 it is sent in the `SOAPAction` header, for SOAP 1.2 as the `action`
+// parameter of the `Content-Type`, unless those headers were set with SetHeader.
+// 		client.R().
+//			SetSOAPAction("http://mywebservice.com/km/add/action")
+//
+func (r *Request) SetSOAPAction(action string) *Request {
+	r.SOAPAction = action
+	return r
+}
+
+// SetSOAPVersion method is to set the SOAP version of the current request.
+// It overrides the version set at client instance level.
+// 		client.R().
+//			SetSOAPVersion(soap.SOAP12)
+//
+func (r *Request) SetSOAPVersion(version SOAPVersion) *Request {
+	r.SOAPVersion = version
+	return r
+}
+
+// SetWSSecurity method is to set the WS-Security header added to the envelope of the current request.
+// It overrides the WS-Security set at client instance level.
+// 		client.R().
+//			SetWSSecurity(&soap.WSSecurity{
+//				UsernameToken: &soap.UsernameToken{
+//					Username:     "user",
+//					Password:     "secret",
+//					PasswordType: soap.PasswordDigest,
+//				},
+//				TimestampTTL: 5 * time.Minute,
+//			})
+//
+func (r *Request) SetWSSecurity(security *WSSecurity) *Request {
+	r.WSSecurity = security
+	return r
+}
+
+// SetAddressing method is to set the WS-Addressing headers added to the envelope of the current request.
+// It overrides the WS-Addressing set at client instance level.
+// 		client.R().
+//			SetAddressing(&soap.Addressing{
+//				Namespace: soap.WSAddressing200408Namespace,
+//				ReplyTo:   "http://client.example.com/replies",
+//			})
+//
+func (r *Request) SetAddressing(addressing *Addressing) *Request {
+	r.Addressing = addressing
+	return r
+}
+
+// Operation method returns the name of the operation called by the current request, the local name of
+// its payload request element, e.g. `NumberToWords`, or its SOAPAction when the payload is an envelope.
+func (r *Request) Operation() string {
+	if name := operationName(r.PayloadRequest); name != "" {
+		return name
+	}
+	return r.SOAPAction
+}
+
+// Version method returns the SOAP version of the current request, falling back to the version set at
+// client instance level and then to SOAP 1.1.
+func (r *Request) Version() SOAPVersion {
+	return r.soapVersion()
+}
+
+// MessageID method returns the wsa:MessageID sent by the last call of the current request.
+func (r *Request) MessageID() string {
+	return r.messageID
+}
+
+// SetMTOM method is to send the current request as an MTOM `multipart/related` message. The non empty
+// `[]byte` and `io.Reader` fields of the payload are sent as binary XOP parts instead of inline text.
+// It is enabled when set at the request or at the client instance level.
+// 		client.R().
+//			SetMTOM(true)
+//
+func (r *Request) SetMTOM(enabled bool) *Request {
+	r.MTOM = enabled
+	return r
+}
+
+// AddAttachment method is to add a part sent along with the envelope of the current request. The request
+// is sent as a SOAP with Attachments `multipart/related` message, the envelope referring to the part with
+// a `cid:` URI of its content id. The content is read when the request is called.
+// 		file, _ := os.Open("invoice.pdf")
+// 		client.R().
+//			AddAttachment("invoice@example.com", "application/pdf", file)
+//
+func (r *Request) AddAttachment(contentID, contentType string, content io.Reader) *Request {
+	r.Attachments = append(r.Attachments, &Attachment{
+		ContentID:   contentID,
+		ContentType: contentType,
+		Content:     content,
+	})
+	return r
+}
+
+// SetStreaming method is to encode the envelope of the current request while it is sent and to decode the
+// payload response while it is received, instead of holding them in memory. WS-Addressing, WS-Security,
+// MTOM and attachments need the whole envelope, the request is then encoded in memory, and the response
+// is read whole when it is verified, decrypted, multipart or decoded into an envelope type. The streamed
+// payload response is not available from `Response.PayloadResponse`.
+// It is enabled when set at the request or at the client instance level.
+// 		client.R().
+//			SetStreaming(true).
+//			SetMaxResponseSize(512 << 20)
+//
+func (r *Request) SetStreaming(enabled bool) *Request {
+	r.Streaming = enabled
+	return r
+}
+
+// SetMaxResponseSize method is to set the maximum size in bytes of the response body of the current
+// request, a larger response fails with an `ErrRead` error caused by `ErrResponseTooLarge`.
+// It overrides the maximum set at client instance level, zero means no limit.
+// 		client.R().
+//			SetMaxResponseSize(10 << 20)
+//
+func (r *Request) SetMaxResponseSize(size int64) *Request {
+	r.MaxResponseSize = size
+	return r
+}
+
+// SetDeferDecoding method is to leave the Body of the response of the current request to be decoded element
+// by element with `Response.Elements` or `Response.Each` while it is received, instead of decoding the
+// payload response. Call still returns the SOAP Fault or the HTTP status error of the response, the response
+// body is then closed by the iteration or by `Response.Close`. A response that must be read whole, see
+// SetStreaming, is iterated from the received envelope.
+// 		resp, err := client.R().
+//			SetDeferDecoding(true).
+//			Call()
+//		if err != nil {
+//			return err
+//		}
+//		defer resp.Close()
+//
+func (r *Request) SetDeferDecoding(enabled bool) *Request {
+	r.DeferDecoding = enabled
+	return r
+}
+
+// SetRetryPolicy method is to set the retry policy of the current request, see RetryPolicy.
+// It overrides the retry policy set at client instance level.
+// 		client.R().
+//			SetRetryPolicy(&soap.RetryPolicy{
+//				MaxAttempts:    5,
+//				InitialBackoff: 200 * time.Millisecond,
+//				Jitter:         0.5,
+//				FaultCodes:     []string{"Server"},
+//			})
+//
+func (r *Request) SetRetryPolicy(policy *RetryPolicy) *Request {
+	r.RetryPolicy = policy
+	return r
+}
+
+// SetIdempotent method is to mark the operation of the current request as idempotent, so that it is
+// retried by the retry policy. Requests are not retried otherwise, unless their SOAPAction is one of
+// the policy IdempotentActions.
+// 		client.R().
+//			SetSOAPAction("http://mywebservice.com/km/get").
+//			SetIdempotent(true)
+//
+func (r *Request) SetIdempotent(idempotent bool) *Request {
+	r.Idempotent = idempotent
+	return r
+}
+
+// OnBeforeSend method is to append a hook called before each attempt of the current request, after the
+// hooks set at client instance level. It can change the HTTP request headers and the envelope sent,
+// see BeforeSendHook. The envelope of a request with before-send hooks is not streamed.
+// 		client.R().
+//			OnBeforeSend(func(r *soap.Request, req *http.Request, envelope []byte) ([]byte, error) {
+//				req.Header.Set("X-Signature", sign(envelope))
+//				return envelope, nil
+//			})
+//
+func (r *Request) OnBeforeSend(hook BeforeSendHook) *Request {
+	r.beforeSend = append(r.beforeSend, hook)
+	return r
+}
+
+// OnAfterReceive method is to append a hook called with each response of the current request before it is
+// decoded, after the hooks set at client instance level. It can change the response headers and the body
+// decoded, see AfterReceiveHook. The response of a request with after-receive hooks is not streamed.
+// 		client.R().
+//			OnAfterReceive(func(resp *soap.Response, body []byte) ([]byte, error) {
+//				return bytes.ReplaceAll(body, []byte("<Price>N/A</Price>"), nil), nil
+//			})
+//
+func (r *Request) OnAfterReceive(hook AfterReceiveHook) *Request {
+	r.afterReceive = append(r.afterReceive, hook)
+	return r
+}
+
+// OnError method is to append a hook called when the current request fails, after the hooks set at client
+// instance level. It can replace the error returned by Call, see ErrorHook.
+// 		client.R().
+//			OnError(func(r *soap.Request, resp *soap.Response, err error) error {
+//				return fmt.Errorf("get quote: %w", err)
+//			})
+//
+func (r *Request) OnError(hook ErrorHook) *Request {
+	r.onError = append(r.onError, hook)
+	return r
+}
+
 // SetHeaders method sets multiple headers field and its values at one go in the current request.
 //
 // For Example: To set `Content-Type` and `Accept` as `text/xml; charset=utf-8`
@@ -100,6 +333,17 @@ func (r *Request) SetHeaders(headers map[string]string) *Request {
 // 		client.R().
 //			SetPayloadRequest(&payload)
 //
+// The payload can also be just the operation element, Call wraps it in a generated
+// `soap:Envelope` when its XMLName is not an Envelope.
+//
+//  `type NumberToWords struct {
+//		XMLName xml.Name `xml:"http://www.my.com/webservicesserver/ NumberToWords"`
+//		Number  string   `xml:"Number"`
+//	}`
+//
+// 		client.R().
+//			SetPayloadRequest(&NumberToWords{Number: "777"})
+//
 func (r *Request) SetPayloadRequest(payloadRequest interface{}) *Request {
 	r.PayloadRequest = payloadRequest
 	return r
@@ -135,6 +379,9 @@ func (r *Request) SetPayloadRequest(payloadRequest interface{}) *Request {
 // 		client.R().
 //			SetPayloadResponse(&NumberToWordsResponse{})
 //
+// When the response type is not an Envelope, Call decodes the first child of
+// `soap:Body` into it, so the operation response element is enough.
+//
 func (r *Request) SetPayloadResponse(payloadResponse interface{}) *Request {
 	r.PayloadResponse = getPointer(payloadResponse)
 	return r
@@ -172,54 +419,425 @@ func (r *Request) SetPayloadResponse(payloadResponse interface{}) *Request {
 // 		client.R().
 //			SetPayloadFault(&NumberToWordsFault{})
 //
+// When the fault type is not an Envelope, Call decodes the `soap:Fault` element into it.
+// It overrides the fault set at client instance level.
+//
 func (r *Request) SetPayloadFault(payloadFault interface{}) *Request {
 	r.PayloadFault = getPointer(payloadFault)
 	return r
 }
 
-// The Call method Execute the request
+//  SetFaultDetail method is to set the struct decoded from the fault detail entry in the current request.
+//
+// For Example: To read `<detail>
+//							<InvalidNumber xmlns="http://www.my.com/webservicesserver/">
+//								<Number>-1</Number>
+//							</InvalidNumber>
+//						</detail>`.
+//
+//  `type InvalidNumber struct {
+//		XMLName xml.Name `xml:"http://www.my.com/webservicesserver/ InvalidNumber"`
+//		Number  string   `xml:"Number"`
+//	}`
+//
+// 		_, err := client.R().
+//			SetFaultDetail(&InvalidNumber{}).
+//			Call()
+//
+//		var fault *soap.Fault
+//		if errors.As(err, &fault) {
+//			detail := fault.DetailValue.(*InvalidNumber)
+//		}
+//
+func (r *Request) SetFaultDetail(detail interface{}) *Request {
+	r.FaultDetail = getPointer(detail)
+	return r
+}
+
+// SetContext method sets the context.Context of the current request. Its
+// cancellation and deadline apply to the HTTP round-trip and the response body read.
+// 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// 		defer cancel()
+// 		client.R().
+//			SetContext(ctx)
+//
+func (r *Request) SetContext(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
+// Context method returns the context.Context of the current request,
+// context.Background() if none was set.
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// CallContext method executes the request with the given context.
+// 		resp, err := client.R().
+//			SetPayloadRequest(&payload).
+//			CallContext(ctx)
+//
+func (r *Request) CallContext(ctx context.Context) (*Response, error) {
+	return r.SetContext(ctx).Call()
+}
+
+// The Call method Execute the request.
+//
+// The middlewares of the client wrap the call with all its attempts. The
+// before-send and after-receive hooks of the client and of the request
+// are called around each attempt, the error hooks once the call failed.
+//
+// With a retry policy, the idempotent requests that fail with a transient
+// error are attempted again, the response and the error of the last attempt
+// being returned.
+//
+// When the response Body contains a SOAP Fault, Call returns the response
+// along with a *Fault error. Any other failure is returned as an *Error
+// matching one of ErrMarshal, ErrRequest, ErrTransport, ErrRead, ErrDecode,
+// ErrStatus or ErrCircuitOpen with errors.Is.
 func (r *Request) Call() (*Response, error) {
+	if r.client != nil {
+		r.Url = r.client.resolveURL(r.Url)
+	}
+	response, err := r.chain()(r)
+	r.logError(response, err)
+	for _, hook := range r.errorHooks() {
+		if err == nil {
+			break
+		}
+		err = hook(r, response, err)
+	}
+	return response, err
+}
 
-	marshalRequest, _ := xml.Marshal(r.PayloadRequest)
-	req, err := http.NewRequest("POST", r.Url, bytes.NewReader(marshalRequest))
+// call executes the attempts of the request.
+func (r *Request) call() (*Response, error) {
+	version := r.soapVersion()
+	streamed := r.streamsRequest()
+	var envelope []byte
+	var parts []*mimePart
+	if !streamed {
+		var err error
+		if envelope, parts, err = r.marshal(version); err != nil {
+			return nil, err
+		}
+	}
+	policy := r.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		response, err := r.client.breaker.call(r, func() (*Response, error) {
+			// Each attempt has its own WS-Security nonce and timestamp.
+			var body []byte
+			var contentType string
+			if !streamed {
+				var err error
+				if body, contentType, err = r.seal(version, envelope, parts); err != nil {
+					return nil, err
+				}
+			}
+			response, err := r.send(version, streamed, body, contentType)
+			r.record(attempt, response, err)
+			return response, err
+		})
+		if !policy.retries(r, attempt, response, err) {
+			return response, err
+		}
+		if response != nil {
+			response.Close()
+		}
+		if !policy.wait(r.Context(), attempt) {
+			return response, err
+		}
+	}
+}
+
+// send makes one attempt of the call with the marshaled envelope, or with
+// the envelope encoded while it is sent when streamed.
+func (r *Request) send(version SOAPVersion, streamed bool, envelope []byte, contentType string) (*Response, error) {
+	var body io.Reader = bytes.NewReader(envelope)
+	var pipe *io.PipeWriter
+	var sent int64
+	if streamed {
+		var reader *io.PipeReader
+		reader, pipe = io.Pipe()
+		body = &countingReader{r: reader, n: &sent}
+	}
+	req, err := http.NewRequestWithContext(r.Context(), "POST", r.Url, body)
 	if err != nil {
-		log.Fatalf("failed to create POST request %s", err)
-		return nil, err
+		return nil, newError(ErrRequest, err)
 	}
 	// Create headers
+	version.setHeaders(r.Header, r.SOAPAction)
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
 	req.Header = r.Header
 	req.Close = true
+	r.RawRequest = req
+	if !streamed {
+		for _, hook := range r.beforeSendHooks() {
+			if envelope, err = hook(r, req, envelope); err != nil {
+				return nil, err
+			}
+		}
+		sent = int64(len(envelope))
+		req.Body, req.ContentLength = ioutil.NopCloser(bytes.NewReader(envelope)), sent
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(envelope)), nil
+		}
+		r.logRequest(&bodyLog{contentType: req.Header.Get("Content-Type"), data: envelope})
+	} else {
+		r.logRequest(nil)
+	}
+
+	// A streamed envelope is encoded while the transport sends it.
+	encoded := make(chan error, 1)
+	if pipe != nil {
+		go func() {
+			err := encodeEnvelope(pipe, version, r.PayloadRequest)
+			encoded <- err
+			pipe.CloseWithError(err)
+		}()
+	}
 
 	r.Time = time.Now()
 	resp, err := r.client.httpClient.Do(req)
 	endTime := time.Now()
 	if err != nil {
+		select {
+		case encodeErr := <-encoded:
+			// The transport closing the body fails the encoding with io.ErrClosedPipe.
+			if encodeErr != nil && !errors.Is(encodeErr, io.ErrClosedPipe) {
+				return nil, newError(ErrMarshal, encodeErr)
+			}
+		default:
+		}
 		// failed to send request
-		return nil, err
+		return nil, newError(ErrTransport, err)
 	}
 
-	defer resp.Body.Close()
-
 	response := &Response{
 		Request:     r,
 		RawResponse: resp,
 		receivedAt:  endTime,
+		requestSize: atomic.LoadInt64(&sent),
 	}
+	defer func() {
+		// A deferred Body is closed once its elements are read.
+		if response.stream == nil {
+			resp.Body.Close()
+		}
+	}()
 
-	if response.payloadResponse, err = ioutil.ReadAll(resp.Body); err != nil {
-		return nil, err
+	responseBody := io.Reader(&countingReader{r: resp.Body, n: &response.size})
+	if max := r.maxResponseSize(); max > 0 {
+		responseBody = &limitedReader{r: responseBody, remaining: max}
+	}
+	if r.streamsResponse(resp) {
+		r.logResponse(response, nil)
+		return r.decodeStream(response, responseBody)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		err := xml.Unmarshal(response.payloadResponse, response.Request.PayloadFault)
+	if response.payloadResponse, err = ioutil.ReadAll(responseBody); err != nil {
+		return nil, newError(ErrRead, err)
+	}
+	response.body = response.payloadResponse
+	for _, hook := range r.afterReceiveHooks() {
+		if response.payloadResponse, err = hook(response, response.payloadResponse); err != nil {
+			return response, err
+		}
+	}
+	r.logResponse(response, &bodyLog{contentType: resp.Header.Get("Content-Type"), data: response.payloadResponse})
+	if response.payloadResponse, response.parts, err = readMultipart(resp.Header.Get("Content-Type"), response.payloadResponse); err != nil {
+		return response, newError(ErrDecode, err)
+	}
+	if security := r.wsSecurity(); security != nil {
+		opened, err := security.open(response.payloadResponse)
 		if err != nil {
-			log.Printf("filed trying to convert fault fault response %s", err)
 			return response, err
 		}
-		return response, err
+		response.payloadResponse = opened
 	}
-	err = xml.Unmarshal(response.payloadResponse, response.Request.PayloadResponse)
-	return response, err
+	return r.decode(response)
+}
+
+// marshal returns the envelope of the request, with its WS-Addressing
+// headers, and its XOP and attachment parts.
+func (r *Request) marshal(version SOAPVersion) ([]byte, []*mimePart, error) {
+	var marshalRequest []byte
+	var parts []*mimePart
+	var err error
+	if r.mtom() {
+		marshalRequest, parts, err = marshalMTOM(version, r.PayloadRequest)
+	} else {
+		marshalRequest, err = marshalEnvelope(version, r.PayloadRequest)
+	}
+	if err != nil {
+		return nil, nil, newError(ErrMarshal, err)
+	}
+	if addressing := r.addressing(); addressing != nil {
+		if r.messageID = addressing.MessageID; r.messageID == "" {
+			if r.messageID, err = newMessageID(); err != nil {
+				return nil, nil, newError(ErrMarshal, err)
+			}
+		}
+		to, action := addressing.To, addressing.Action
+		if to == "" {
+			to = r.Url
+		}
+		if action == "" {
+			action = r.SOAPAction
+		}
+		if marshalRequest, err = addressing.address(marshalRequest, to, action, r.messageID); err != nil {
+			return nil, nil, newError(ErrMarshal, err)
+		}
+	}
+	// The attachments are read once, for all the attempts.
+	for _, attachment := range r.Attachments {
+		part, err := attachment.part()
+		if err != nil {
+			return nil, nil, newError(ErrMarshal, err)
+		}
+		parts = append(parts, part)
+	}
+	return marshalRequest, parts, nil
+}
+
+// seal returns the body sent by an attempt, the envelope with its
+// WS-Security header, and the content type of a multipart body.
+func (r *Request) seal(version SOAPVersion, envelope []byte, parts []*mimePart) ([]byte, string, error) {
+	var err error
+	if security := r.wsSecurity(); security != nil {
+		if envelope, err = security.secure(envelope); err != nil {
+			return nil, "", newError(ErrMarshal, err)
+		}
+	}
+	if !r.mtom() && len(r.Attachments) == 0 {
+		return envelope, "", nil
+	}
+	body, contentType, err := writeMultipart(version, r.SOAPAction, envelope, parts, r.mtom())
+	if err != nil {
+		return nil, "", newError(ErrMarshal, err)
+	}
+	return body, contentType, nil
+}
+
+// decode returns the response along with its SOAP Fault, or decodes it into
+// the payload response.
+func (r *Request) decode(response *Response) (*Response, error) {
+	var err error
+	if response.fault, err = parseFault(response.payloadResponse); err == nil && response.fault != nil {
+		// The fault is the error of the call, a detail or payload fault
+		// that does not decode is left empty.
+		if r.FaultDetail != nil && response.fault.DecodeDetail(r.FaultDetail) == nil {
+			response.fault.DetailValue = r.FaultDetail
+		}
+		if r.PayloadFault != nil {
+			_ = unmarshalParts(response.payloadResponse, response.parts, r.PayloadFault)
+		}
+		return response, response.fault
+	}
+
+	if r.emptyReply(response, response.payloadResponse) {
+		return response, nil
+	}
+	if response.StatusCode() != http.StatusOK {
+		// A response without SOAP Fault is decoded in the payload fault
+		// when it can be, its status being the error of the call.
+		if r.PayloadFault != nil {
+			_ = unmarshalParts(response.payloadResponse, response.parts, r.PayloadFault)
+		}
+		return response, newError(ErrStatus, errors.New(response.Status()))
+	}
+	if r.PayloadResponse == nil {
+		return response, nil
+	}
+	if err := unmarshalParts(response.payloadResponse, response.parts, r.PayloadResponse); err != nil {
+		return response, newError(ErrDecode, err)
+	}
+	return response, nil
+}
+
+// emptyReply reports whether the response is a 2xx reply without body to a
+// request expecting no payload response, such as the 202 Accepted of a
+// one-way operation.
+func (r *Request) emptyReply(response *Response, body []byte) bool {
+	status := response.StatusCode()
+	return status >= 200 && status < 300 && r.PayloadResponse == nil && len(bytes.TrimSpace(body)) == 0
+}
+
+// soapVersion returns the SOAP version of the request, falling back to
+// the client version and then to SOAP 1.1.
+func (r *Request) soapVersion() SOAPVersion {
+	if r.SOAPVersion != 0 {
+		return r.SOAPVersion
+	}
+	if r.client != nil && r.client.soapVersion != 0 {
+		return r.client.soapVersion
+	}
+	return SOAP11
+}
+
+// wsSecurity returns the WS-Security of the request, falling back to the
+// client WS-Security.
+func (r *Request) wsSecurity() *WSSecurity {
+	if r.WSSecurity != nil {
+		return r.WSSecurity
+	}
+	if r.client != nil {
+		return r.client.wsSecurity
+	}
+	return nil
+}
+
+// addressing returns the WS-Addressing of the request, falling back to the
+// client WS-Addressing.
+func (r *Request) addressing() *Addressing {
+	if r.Addressing != nil {
+		return r.Addressing
+	}
+	if r.client != nil {
+		return r.client.addressing
+	}
+	return nil
+}
+
+// mtom reports whether the request is sent with MTOM, at the request or
+// at the client level.
+func (r *Request) mtom() bool {
+	return r.MTOM || (r.client != nil && r.client.mtom)
+}
+
+// streaming reports whether the request is streamed, at the request or at
+// the client level.
+func (r *Request) streaming() bool {
+	return r.Streaming || (r.client != nil && r.client.streaming)
+}
+
+// maxResponseSize returns the maximum response size of the request,
+// falling back to the client maximum.
+func (r *Request) maxResponseSize() int64 {
+	if r.MaxResponseSize > 0 {
+		return r.MaxResponseSize
+	}
+	if r.client != nil {
+		return r.client.maxResponse
+	}
+	return 0
+}
+
+// retryPolicy returns the retry policy of the request, falling back to the
+// client retry policy.
+func (r *Request) retryPolicy() *RetryPolicy {
+	if r.RetryPolicy != nil {
+		return r.RetryPolicy
+	}
+	if r.client != nil {
+		return r.client.retryPolicy
+	}
+	return nil
 }
 
 func getPointer(v interface{}) interface{} {
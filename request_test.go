@@ -1,8 +1,12 @@
 package soap
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
@@ -21,7 +25,7 @@ func TestRequest_Call(t *testing.T) {
 	}
 
 	// Create dummy service
-	go createDummyWebService()
+	createDummyWebService()
 
 	headers := http.Header{}
 	headers.Set("Content-Type", "text/xml; charset=utf-8")
@@ -678,6 +682,9 @@ func Test_getPointer(t *testing.T) {
 	}
 }
 
+// createDummyWebService listens on :3000 before returning, so that the first
+// call of a test does not race the server start. It keeps the server started
+// by an earlier test when the port is already bound.
 func createDummyWebService() {
 	response := `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" >
 			   <soapenv:Header/>
@@ -694,7 +701,11 @@ func createDummyWebService() {
 		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
 		w.Write([]byte(response))
 	})
-	http.ListenAndServe(":3000", mux)
+	listener, err := net.Listen("tcp", ":3000")
+	if err != nil {
+		return
+	}
+	go http.Serve(listener, mux)
 }
 
 type DummyRequest struct {
@@ -739,3 +750,184 @@ type DummyFault struct {
 		} `xml:"Fault"`
 	} `xml:"Body"`
 }
+
+func TestRequest_SetSOAPAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		want   string
+	}{
+		{
+			name:   "Test Set SOAP Action",
+			action: "http://mywebservice.com/km/add/action",
+			want:   "http://mywebservice.com/km/add/action",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New().R().SetSOAPAction(tt.action); got.SOAPAction != tt.want {
+				t.Errorf("SetSOAPAction() = %v, want %v", got.SOAPAction, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_SetSOAPVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *Client
+		version SOAPVersion
+		want    SOAPVersion
+	}{
+		{
+			name:   "Test default SOAP Version",
+			client: New(),
+			want:   SOAP11,
+		},
+		{
+			name:   "Test inherit client SOAP Version",
+			client: New().SetSOAPVersion(SOAP12),
+			want:   SOAP12,
+		},
+		{
+			name:    "Test override client SOAP Version",
+			client:  New().SetSOAPVersion(SOAP12),
+			version: SOAP11,
+			want:    SOAP11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.client.R()
+			if tt.version != 0 {
+				r.SetSOAPVersion(tt.version)
+			}
+			if got := r.soapVersion(); got != tt.want {
+				t.Errorf("SetSOAPVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_SetContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want context.Context
+	}{
+		{
+			name: "Test default Context",
+			ctx:  nil,
+			want: context.Background(),
+		},
+		{
+			name: "Test Set Context",
+			ctx:  ctx,
+			want: ctx,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New().R().SetContext(tt.ctx).Context(); got != tt.want {
+				t.Errorf("SetContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_CallContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		if r.URL.Path == "/body" {
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>`))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "Test deadline during round-trip", url: server.URL + "/roundtrip"},
+		{name: "Test deadline during body read", url: server.URL + "/body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := New().R().
+				SetUrl(tt.url).
+				SetPayloadRequest(&NumberToWords{UbiNum: "1"}).
+				SetPayloadResponse(&NumberToWordsResponse{}).
+				CallContext(ctx)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("CallContext() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestRequest_SetWSSecurity(t *testing.T) {
+	clientSecurity := &WSSecurity{TimestampTTL: time.Minute}
+	requestSecurity := &WSSecurity{UsernameToken: &UsernameToken{Username: "user"}}
+
+	tests := []struct {
+		name     string
+		client   *Client
+		security *WSSecurity
+		want     *WSSecurity
+	}{
+		{name: "Test default WS-Security", client: New(), want: nil},
+		{name: "Test inherit client WS-Security", client: New().SetWSSecurity(clientSecurity), want: clientSecurity},
+		{name: "Test override client WS-Security", client: New().SetWSSecurity(clientSecurity), security: requestSecurity, want: requestSecurity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.client.R()
+			if tt.security != nil {
+				r.SetWSSecurity(tt.security)
+			}
+			if got := r.wsSecurity(); got != tt.want {
+				t.Errorf("SetWSSecurity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_SetAddressing(t *testing.T) {
+	clientAddressing := &Addressing{MustUnderstand: true}
+	requestAddressing := &Addressing{Namespace: WSAddressing200408Namespace}
+
+	tests := []struct {
+		name       string
+		client     *Client
+		addressing *Addressing
+		want       *Addressing
+	}{
+		{name: "Test default WS-Addressing", client: New(), want: nil},
+		{name: "Test inherit client WS-Addressing", client: New().SetAddressing(clientAddressing), want: clientAddressing},
+		{name: "Test override client WS-Addressing", client: New().SetAddressing(clientAddressing), addressing: requestAddressing, want: requestAddressing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.client.R()
+			if tt.addressing != nil {
+				r.SetAddressing(tt.addressing)
+			}
+			if got := r.addressing(); got != tt.want {
+				t.Errorf("SetAddressing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
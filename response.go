@@ -1,7 +1,13 @@
 package soap
 
 import (
+	"bytes"
+	"encoding/xml"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -10,10 +16,18 @@ type Response struct {
 	Request         *Request
 	RawResponse     *http.Response
 	payloadResponse []byte
+	body            []byte
 	receivedAt      time.Time
+	fault           *Fault
+	parts           []*mimePart
+	stream          *bodyStream
+	requestSize     int64
+	size            int64
 }
 
 // Body method returns HTTP response as []byte array for the executed request.
+// The root part of a multipart/related response is returned, its other parts
+// being resolved in the payload response.
 func (r *Response) PayloadResponse() []byte {
 	if r.RawResponse == nil {
 		return []byte{}
@@ -49,7 +63,122 @@ func (r *Response) PayloadResultError() interface{} {
 	return r.Request.PayloadFault
 }
 
+// Fault method returns the SOAP Fault received for the request, nil if the response has none.
+func (r *Response) Fault() *Fault {
+	return r.fault
+}
+
 // ReceivedAt method returns when response got recevied from server for the request.
 func (r *Response) ReceivedAt() time.Time {
 	return r.receivedAt
 }
+
+// Size method returns the number of bytes of the response body read, as received before its multipart
+// parts are split or its content decrypted. The body of a request that deferred its decoding is counted
+// while its elements are read.
+func (r *Response) Size() int64 {
+	return atomic.LoadInt64(&r.size)
+}
+
+// RequestSize method returns the number of bytes of the request body sent by the attempt of the response.
+func (r *Response) RequestSize() int64 {
+	return r.requestSize
+}
+
+// RelatesTo method returns the wsa:RelatesTo header of the response, the
+// MessageID of the request it replies to with WS-Addressing.
+func (r *Response) RelatesTo() string {
+	return relatesTo(r.payloadResponse)
+}
+
+// Attachments method returns the parts received along with the envelope in a
+// `multipart/related` response, XOP parts included.
+func (r *Response) Attachments() []*Attachment {
+	var attachments []*Attachment
+	for _, part := range r.parts {
+		attachments = append(attachments, part.attachment())
+	}
+	return attachments
+}
+
+// Attachment method returns the received part with the content id, given
+// with or without its `cid:` scheme, nil if the response has none.
+func (r *Response) Attachment(contentID string) *Attachment {
+	if strings.HasPrefix(contentID, "cid:") {
+		if id, err := url.PathUnescape(contentID[len("cid:"):]); err == nil {
+			contentID = id
+		}
+	}
+	contentID = strings.Trim(contentID, "<>")
+	if part := findPart(r.parts, contentID); part != nil {
+		return part.attachment()
+	}
+	return nil
+}
+
+// Elements method returns an iterator over the elements at the path inside the Body, a `/` separated list
+// of element local names starting with the Body child, e.g. `GetCatalogResponse/Items/Item`. When the request
+// deferred its decoding, the elements are decoded while the body is received and can be iterated only once,
+// else they are decoded from the received envelope.
+//		elements := resp.Elements("GetCatalogResponse/Items/Item")
+//		defer elements.Close()
+//		for elements.Next() {
+//			item := &Item{}
+//			if err := elements.Decode(item); err != nil {
+//				return err
+//			}
+//		}
+//		if err := elements.Err(); err != nil {
+//			return err
+//		}
+//
+func (r *Response) Elements(path string) *Elements {
+	if r.stream != nil {
+		if r.stream.d == nil {
+			return &Elements{done: true, err: newError(ErrRead, errBodyRead)}
+		}
+		stream := *r.stream
+		r.stream.d = nil
+		return newElements(stream.d, stream.start, path, stream.body)
+	}
+	d := xml.NewDecoder(bytes.NewReader(r.payloadResponse))
+	start, err := bodyElement(d)
+	if err != nil {
+		return &Elements{done: true, err: newError(ErrDecode, err)}
+	}
+	return newElements(d, start, path, nil)
+}
+
+// Each method decodes each element at the path inside the Body into v, a pointer reset to its zero value
+// before each element, and calls fn after it. An error returned by fn stops the iteration and is returned.
+// See Response.Elements for the path.
+//		item := &Item{}
+//		err := resp.Each("GetCatalogResponse/Items/Item", item, func() error {
+//			return store.Save(item)
+//		})
+//
+func (r *Response) Each(path string, v interface{}, fn func() error) error {
+	elements := r.Elements(path)
+	defer elements.Close()
+	value := reflect.ValueOf(v).Elem()
+	for elements.Next() {
+		value.Set(reflect.Zero(value.Type()))
+		if err := elements.Decode(v); err != nil {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return elements.Err()
+}
+
+// Close method closes the response body left open by a request that deferred its decoding, when its
+// elements are not iterated to the end.
+func (r *Response) Close() error {
+	if r.stream == nil || r.stream.d == nil {
+		return nil
+	}
+	r.stream.d = nil
+	return r.stream.body.Close()
+}
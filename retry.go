@@ -0,0 +1,175 @@
+package soap
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts of a RetryPolicy without MaxAttempts.
+	DefaultMaxAttempts = 3
+	// DefaultInitialBackoff is the delay before the first retry of a RetryPolicy without InitialBackoff.
+	DefaultInitialBackoff = 100 * time.Millisecond
+	// DefaultMaxBackoff is the longest delay between attempts of a RetryPolicy without MaxBackoff.
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+var (
+	// DefaultRetryStatusCodes are the HTTP status codes of the responses
+	// retried by a RetryPolicy without StatusCodes.
+	DefaultRetryStatusCodes = []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+	// DefaultRetryFaultCodes are the fault codes, without their prefix, of
+	// the SOAP Faults retried by a RetryPolicy without FaultCodes. They are
+	// the server side faults of SOAP 1.1 and SOAP 1.2, a `soap:Client` or
+	// `env:Sender` fault fails the same way when retried.
+	DefaultRetryFaultCodes = []string{"Server", "Receiver"}
+)
+
+// RetryPolicy struct configures the attempts of a call that fails with a
+// transient error. The delay between two attempts grows exponentially from
+// InitialBackoff up to MaxBackoff, a Jitter fraction of it being random.
+//
+// Only idempotent operations are retried: the requests set with
+// Request.SetIdempotent, or whose SOAPAction is one of IdempotentActions.
+// The envelope is marshaled once and sent again, attachments and io.Reader
+// fields included, its WS-Security header being made again with a new
+// nonce, timestamp and signature for each attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, the first one included,
+	// DefaultMaxAttempts when zero.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Multiplier is the growth factor of the delay, 2 when lower than 1.
+	Multiplier float64
+	// Jitter is the fraction of each delay that is random, from 0 to 1.
+	Jitter float64
+	// StatusCodes are the HTTP status codes retried, DefaultRetryStatusCodes
+	// when nil.
+	StatusCodes []int
+	// FaultCodes are the SOAP Fault codes retried, without their prefix,
+	// DefaultRetryFaultCodes when nil. A dotted SOAP 1.1 code such as
+	// `soap:Server.Timeout` matches `Server`.
+	FaultCodes []string
+	// IdempotentActions are the SOAPActions of the operations that can be
+	// retried.
+	IdempotentActions []string
+	// Retry, when set, replaces the transport error, status code and fault
+	// code predicates. It is called with the response and the error of an
+	// attempt of an idempotent operation.
+	Retry func(resp *Response, err error) bool
+}
+
+// retries reports whether the attempt of the request is followed by another.
+func (p *RetryPolicy) retries(r *Request, attempt int, resp *Response, err error) bool {
+	if p == nil || attempt >= p.maxAttempts() || r.Context().Err() != nil || !p.idempotent(r) {
+		return false
+	}
+	if p.Retry != nil {
+		return p.Retry(resp, err)
+	}
+	var fault *Fault
+	switch {
+	case errors.As(err, &fault):
+		return p.retriesFault(fault)
+	case errors.Is(err, ErrTransport):
+		return true
+	case errors.Is(err, ErrRead):
+		return !errors.Is(err, ErrResponseTooLarge)
+	case resp != nil:
+		return p.retriesStatus(resp.StatusCode())
+	}
+	return false
+}
+
+// idempotent reports whether the request can be sent again.
+func (p *RetryPolicy) idempotent(r *Request) bool {
+	if r.Idempotent {
+		return true
+	}
+	for _, action := range p.IdempotentActions {
+		if action == r.SOAPAction {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retriesFault(fault *Fault) bool {
+	codes := p.FaultCodes
+	if codes == nil {
+		codes = DefaultRetryFaultCodes
+	}
+	code := fault.CodeLocal()
+	for _, c := range codes {
+		if code == c || strings.HasPrefix(code, c+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retriesStatus(status int) bool {
+	codes := p.StatusCodes
+	if codes == nil {
+		codes = DefaultRetryStatusCodes
+	}
+	for _, c := range codes {
+		if status == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+// backoff returns the delay after the attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	initial, max, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// wait sleeps the delay after the attempt, it returns false when the
+// context is done first.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
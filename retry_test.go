@@ -0,0 +1,205 @@
+package soap
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequest_Call_RetryPolicy(t *testing.T) {
+	serverFault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server.Timeout</faultcode><faultstring>busy</faultstring></soap:Fault></soap:Body></soap:Envelope>`
+	clientFault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>invalid</faultstring></soap:Fault></soap:Body></soap:Envelope>`
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`
+
+	var mu sync.Mutex
+	var bodies []string
+	failures := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		fail := len(bodies) <= failures
+		mu.Unlock()
+		if !fail {
+			w.Write([]byte(response))
+			return
+		}
+		switch r.URL.Path {
+		case "/transport":
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case "/status":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/server":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(serverFault))
+		case "/client":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(clientFault))
+		case "/internal":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	reset := func(n int) {
+		mu.Lock()
+		defer mu.Unlock()
+		bodies, failures = nil, n
+	}
+	attempts := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies
+	}
+
+	fast := &RetryPolicy{InitialBackoff: time.Millisecond, Jitter: 0.5}
+	tests := []struct {
+		name         string
+		path         string
+		failures     int
+		policy       *RetryPolicy
+		idempotent   bool
+		action       string
+		streaming    bool
+		attachment   bool
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "Transport error", path: "/transport", failures: 2, policy: fast, idempotent: true, wantAttempts: 3},
+		{name: "Status code", path: "/status", failures: 1, policy: fast, idempotent: true, wantAttempts: 2},
+		{name: "Server fault", path: "/server", failures: 2, policy: fast, idempotent: true, wantAttempts: 3},
+		{name: "Client fault", path: "/client", failures: 2, policy: fast, idempotent: true, wantAttempts: 1, wantErr: ErrFault},
+		{name: "Status code not retried", path: "/internal", failures: 1, policy: fast, idempotent: true, wantAttempts: 1, wantErr: ErrStatus},
+		{name: "Attempts exhausted", path: "/status", failures: 5, policy: &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}, idempotent: true, wantAttempts: 2, wantErr: ErrStatus},
+		{name: "Non idempotent", path: "/transport", failures: 1, policy: fast, wantAttempts: 1, wantErr: ErrTransport},
+		{name: "Idempotent action", path: "/transport", failures: 1, policy: &RetryPolicy{InitialBackoff: time.Millisecond, IdempotentActions: []string{"urn:get"}}, action: "urn:get", wantAttempts: 2},
+		{name: "Custom predicate", path: "/internal", failures: 1, policy: &RetryPolicy{InitialBackoff: time.Millisecond, Retry: func(resp *Response, err error) bool {
+			return resp != nil && resp.StatusCode() == http.StatusInternalServerError
+		}}, idempotent: true, wantAttempts: 2},
+		{name: "No policy", path: "/transport", failures: 1, idempotent: true, wantAttempts: 1, wantErr: ErrTransport},
+		{name: "Streamed request", path: "/status", failures: 2, policy: fast, idempotent: true, streaming: true, wantAttempts: 3},
+		{name: "Attachment", path: "/status", failures: 2, policy: fast, idempotent: true, attachment: true, wantAttempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset(tt.failures)
+			result := &NumberToWordsResponse{}
+			r := New().SetRetryPolicy(tt.policy).R().
+				SetUrl(server.URL + tt.path).
+				SetSOAPAction(tt.action).
+				SetIdempotent(tt.idempotent).
+				SetStreaming(tt.streaming).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				SetPayloadResponse(result)
+			if tt.attachment {
+				r.AddAttachment("notes@client", "text/plain", strings.NewReader("notes"))
+			}
+			_, err := r.Call()
+			if tt.wantErr == nil && err != nil || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Call() error = %v, want %v", err, tt.wantErr)
+			}
+			bodies := attempts()
+			if len(bodies) != tt.wantAttempts {
+				t.Fatalf("attempts = %v, want %v", len(bodies), tt.wantAttempts)
+			}
+			for i, body := range bodies {
+				if !strings.Contains(body, "<ubiNum>7</ubiNum>") || (tt.attachment && !strings.Contains(body, "notes")) {
+					t.Errorf("attempt %d body = %s, want the request", i+1, body)
+				}
+			}
+			if tt.wantErr == nil && result.NumberToWordsResult != "seven" {
+				t.Errorf("NumberToWordsResult = %v, want seven", result.NumberToWordsResult)
+			}
+		})
+	}
+
+	t.Run("Request policy", func(t *testing.T) {
+		reset(1)
+		_, err := New().SetRetryPolicy(&RetryPolicy{MaxAttempts: 1}).R().
+			SetUrl(server.URL + "/status").
+			SetRetryPolicy(fast).
+			SetIdempotent(true).
+			SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+			Call()
+		if n := len(attempts()); err != nil || n != 2 {
+			t.Errorf("Call() error = %v after %v attempts, want nil after 2", err, n)
+		}
+	})
+
+	t.Run("WS-Security header of each attempt", func(t *testing.T) {
+		reset(2)
+		_, err := New().R().
+			SetUrl(server.URL + "/status").
+			SetRetryPolicy(fast).
+			SetIdempotent(true).
+			SetWSSecurity(&WSSecurity{
+				UsernameToken: &UsernameToken{Username: "user", Password: "secret", PasswordType: PasswordDigest},
+				TimestampTTL:  time.Minute,
+			}).
+			SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+			Call()
+		if err != nil {
+			t.Fatalf("Call() error = %v", err)
+		}
+		nonce := regexp.MustCompile(`<wsse:Nonce[^>]*>([^<]+)</wsse:Nonce>`)
+		nonces := map[string]bool{}
+		for i, body := range attempts() {
+			match := nonce.FindStringSubmatch(body)
+			if match == nil {
+				t.Fatalf("attempt %d body = %s, want a wsse:Nonce", i+1, body)
+			}
+			nonces[match[1]] = true
+		}
+		if len(nonces) != 3 {
+			t.Errorf("distinct nonces = %v, want one per attempt out of 3", len(nonces))
+		}
+	})
+
+	t.Run("Context done while waiting", func(t *testing.T) {
+		reset(5)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := New().R().
+			SetUrl(server.URL + "/status").
+			SetRetryPolicy(&RetryPolicy{InitialBackoff: time.Minute}).
+			SetIdempotent(true).
+			SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+			CallContext(ctx)
+		if n := len(attempts()); !errors.Is(err, ErrStatus) || n != 1 {
+			t.Errorf("Call() error = %v after %v attempts, want ErrStatus after 1", err, n)
+		}
+	})
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "Default first delay", policy: &RetryPolicy{}, attempt: 1, min: DefaultInitialBackoff, max: DefaultInitialBackoff},
+		{name: "Exponential", policy: &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 3}, attempt: 3, min: 9 * time.Second, max: 9 * time.Second},
+		{name: "Maximum", policy: &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}, attempt: 10, min: 3 * time.Second, max: 3 * time.Second},
+		{name: "Jitter", policy: &RetryPolicy{InitialBackoff: time.Second, Jitter: 0.5}, attempt: 2, min: time.Second, max: 2 * time.Second},
+		{name: "Full jitter", policy: &RetryPolicy{InitialBackoff: time.Second, Jitter: 2}, attempt: 1, min: 0, max: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := tt.policy.backoff(tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("backoff() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package soap
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"time"
+)
+
+// Namespaces and URIs of the WS-Security 1.0 header.
+const (
+	WSSENamespace = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	WSUNamespace  = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+
+	PasswordTextURI   = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordText"
+	PasswordDigestURI = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+	Base64BinaryURI   = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+)
+
+// wsuTime is the time format of the wsu:Created and wsu:Expires elements.
+const wsuTime = "2006-01-02T15:04:05.000Z"
+
+// now returns the current time, replaced in tests.
+var now = time.Now
+
+// PasswordType type is the way the password of a UsernameToken is sent.
+type PasswordType int
+
+const (
+	// PasswordText sends the password in clear text.
+	PasswordText PasswordType = iota
+	// PasswordDigest sends the Base64 SHA-1 digest of the nonce, the
+	// created time and the password.
+	PasswordDigest
+)
+
+// WSSecurity struct configures the `wsse:Security` header added to the
+// request envelope.
+//
+//	client.SetWSSecurity(&soap.WSSecurity{
+//		UsernameToken: &soap.UsernameToken{
+//			Username:     "user",
+//			Password:     "secret",
+//			PasswordType: soap.PasswordDigest,
+//		},
+//		TimestampTTL: 5 * time.Minute,
+//	})
+type WSSecurity struct {
+	// UsernameToken is sent in the header when set.
+	UsernameToken *UsernameToken
+	// TimestampTTL adds a `wsu:Timestamp` expiring after the duration when
+	// positive.
+	TimestampTTL time.Duration
+	// Signer signs the Body and the Timestamp of the envelope when set.
+	Signer *Signer
+	// Verifier checks the signature of the response envelopes when set.
+	Verifier *Verifier
+	// Encrypter encrypts the Body content of the envelope when set.
+	Encrypter *Encrypter
+	// DecryptionKey decrypts the encrypted content of the response
+	// envelopes, usually an *rsa.PrivateKey.
+	DecryptionKey crypto.Decrypter
+}
+
+// UsernameToken struct is the credentials of the WS-Security UsernameToken
+// profile.
+type UsernameToken struct {
+	Username     string
+	Password     string
+	PasswordType PasswordType
+}
+
+type securityHeader struct {
+	XMLName       xml.Name             `xml:"wsse:Security"`
+	Wsse          string               `xml:"xmlns:wsse,attr"`
+	Wsu           string               `xml:"xmlns:wsu,attr"`
+	Attrs         []xml.Attr           `xml:",any,attr"`
+	Timestamp     *securityTimestamp   `xml:"wsu:Timestamp,omitempty"`
+	Token         *binarySecurityToken `xml:"wsse:BinarySecurityToken,omitempty"`
+	UsernameToken *usernameTokenEntry  `xml:"wsse:UsernameToken,omitempty"`
+}
+
+type securityTimestamp struct {
+	ID      string `xml:"wsu:Id,attr"`
+	Created string `xml:"wsu:Created"`
+	Expires string `xml:"wsu:Expires"`
+}
+
+type usernameTokenEntry struct {
+	ID       string         `xml:"wsu:Id,attr"`
+	Username string         `xml:"wsse:Username"`
+	Password securityValue  `xml:"wsse:Password"`
+	Nonce    *securityValue `xml:"wsse:Nonce,omitempty"`
+	Created  string         `xml:"wsu:Created,omitempty"`
+}
+
+// securityValue is a text element with its type or encoding URI.
+type securityValue struct {
+	Type         string `xml:"Type,attr,omitempty"`
+	EncodingType string `xml:"EncodingType,attr,omitempty"`
+	ValueType    string `xml:"ValueType,attr,omitempty"`
+	Value        string `xml:",chardata"`
+}
+
+// header returns the `wsse:Security` header entry of an envelope whose
+// namespace prefix is prefix, the `mustUnderstand` attribute is declared
+// in the envelope namespace.
+func (s *WSSecurity) header(prefix, namespace string) (*securityHeader, error) {
+	created := now().UTC()
+	h := &securityHeader{Wsse: WSSENamespace, Wsu: WSUNamespace}
+	if prefix == "" {
+		prefix = "soapenv"
+		h.Attrs = append(h.Attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: namespace})
+	}
+	h.Attrs = append(h.Attrs, xml.Attr{Name: xml.Name{Local: prefix + ":mustUnderstand"}, Value: "1"})
+
+	if s.TimestampTTL > 0 {
+		h.Timestamp = &securityTimestamp{
+			ID:      "TS-1",
+			Created: created.Format(wsuTime),
+			Expires: created.Add(s.TimestampTTL).Format(wsuTime),
+		}
+	}
+	if s.Signer != nil {
+		h.Token = s.Signer.token()
+	}
+	if t := s.UsernameToken; t != nil {
+		entry := &usernameTokenEntry{
+			ID:       "UsernameToken-1",
+			Username: t.Username,
+			Password: securityValue{Type: PasswordTextURI, Value: t.Password},
+		}
+		if t.PasswordType == PasswordDigest {
+			nonce := make([]byte, 16)
+			if _, err := rand.Read(nonce); err != nil {
+				return nil, err
+			}
+			entry.Created = created.Format(wsuTime)
+			entry.Password = securityValue{Type: PasswordDigestURI, Value: passwordDigest(nonce, entry.Created, t.Password)}
+			entry.Nonce = &securityValue{EncodingType: Base64BinaryURI, Value: base64.StdEncoding.EncodeToString(nonce)}
+		}
+		h.UsernameToken = entry
+	}
+	return h, nil
+}
+
+// passwordDigest returns Base64(SHA-1(nonce + created + password)).
+func passwordDigest(nonce []byte, created, password string) string {
+	h := sha1.New()
+	h.Write(nonce)
+	h.Write([]byte(created))
+	h.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// sendsHeader reports whether a `wsse:Security` header is added to the
+// requests.
+func (s *WSSecurity) sendsHeader() bool {
+	return s.UsernameToken != nil || s.TimestampTTL > 0 || s.Signer != nil || s.Encrypter != nil
+}
+
+// secure adds the `wsse:Security` header to the marshaled envelope, signs
+// it and encrypts its Body. The envelope is left as is when there is nothing to send, e.g.
+// when only the responses are verified.
+func (s *WSSecurity) secure(envelope []byte) ([]byte, error) {
+	if !s.sendsHeader() {
+		return envelope, nil
+	}
+	prefix, namespace, err := envelopeName(envelope)
+	if err != nil {
+		return nil, err
+	}
+	h, err := s.header(prefix, namespace)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := xml.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	secured, err := insertHeader(envelope, entry)
+	if err != nil || (s.Signer == nil && s.Encrypter == nil) {
+		return secured, err
+	}
+
+	root, err := parseXML(secured)
+	if err != nil {
+		return nil, err
+	}
+	header := root.child(root.namespace(), "Header")
+	security := header.child(WSSENamespace, "Security")
+	if s.Signer != nil {
+		if err := s.Signer.sign(root, security); err != nil {
+			return nil, err
+		}
+	}
+	if s.Encrypter != nil {
+		if err := s.Encrypter.encrypt(root, security); err != nil {
+			return nil, err
+		}
+	}
+	return root.bytes(), nil
+}
+
+// open decrypts the response envelope and verifies its signature. A
+// response that is not XML is left to the decoding of the caller.
+func (s *WSSecurity) open(envelope []byte) ([]byte, error) {
+	if s.DecryptionKey != nil {
+		if root, err := parseXML(envelope); err == nil {
+			if err := decrypt(root, s.DecryptionKey); err != nil {
+				return nil, newError(ErrDecrypt, err)
+			}
+			envelope = root.bytes()
+		}
+	}
+	if s.Verifier != nil {
+		if err := s.Verifier.verify(envelope); err != nil {
+			return nil, newError(ErrSignature, err)
+		}
+	}
+	return envelope, nil
+}
@@ -0,0 +1,170 @@
+package soap
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// receivedSecurity is the `wsse:Security` header as decoded by a service.
+type receivedSecurity struct {
+	Header struct {
+		Security struct {
+			MustUnderstand string `xml:"mustUnderstand,attr"`
+			Timestamp      *struct {
+				ID      string `xml:"Id,attr"`
+				Created string `xml:"Created"`
+				Expires string `xml:"Expires"`
+			} `xml:"Timestamp"`
+			UsernameToken *struct {
+				Username string `xml:"Username"`
+				Password struct {
+					Type  string `xml:"Type,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"Password"`
+				Nonce   string `xml:"Nonce"`
+				Created string `xml:"Created"`
+			} `xml:"UsernameToken"`
+		} `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd Security"`
+	} `xml:"Header"`
+	Body struct {
+		NumberToWords *NumberToWords
+	} `xml:"Body"`
+}
+
+func TestRequest_Call_WSSecurity(t *testing.T) {
+	fixed := time.Date(2019, 11, 19, 10, 30, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	defer func() { now = time.Now }()
+
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		security      *WSSecurity
+		wantPassword  string
+		wantType      string
+		wantTimestamp bool
+	}{
+		{
+			name:         "Password text",
+			security:     &WSSecurity{UsernameToken: &UsernameToken{Username: "user", Password: "secret"}},
+			wantPassword: "secret",
+			wantType:     PasswordTextURI,
+		},
+		{
+			name:     "Password digest with timestamp",
+			security: &WSSecurity{UsernameToken: &UsernameToken{Username: "user", Password: "secret", PasswordType: PasswordDigest}, TimestampTTL: 5 * time.Minute},
+			wantType: PasswordDigestURI,
+
+			wantTimestamp: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().SetWSSecurity(tt.security).R().
+				SetUrl(server.URL).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+
+			got := receivedSecurity{}
+			if err := xml.Unmarshal(received, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v\n%s", err, received)
+			}
+			if got.Body.NumberToWords == nil || got.Body.NumberToWords.UbiNum != "7" {
+				t.Errorf("Body = %s, want NumberToWords", received)
+			}
+			security := got.Header.Security
+			if security.MustUnderstand != "1" {
+				t.Errorf("mustUnderstand = %q, want 1", security.MustUnderstand)
+			}
+			token := security.UsernameToken
+			if token == nil || token.Username != "user" || token.Password.Type != tt.wantType {
+				t.Fatalf("UsernameToken = %+v, want user with %v", token, tt.wantType)
+			}
+			if tt.wantPassword != "" && token.Password.Value != tt.wantPassword {
+				t.Errorf("Password = %v, want %v", token.Password.Value, tt.wantPassword)
+			}
+			if tt.security.UsernameToken.PasswordType == PasswordDigest {
+				nonce, err := base64.StdEncoding.DecodeString(token.Nonce)
+				if err != nil || len(nonce) != 16 {
+					t.Errorf("Nonce = %v, want 16 bytes base64", token.Nonce)
+				}
+				if token.Created != "2019-11-19T10:30:00.000Z" {
+					t.Errorf("Created = %v, want 2019-11-19T10:30:00.000Z", token.Created)
+				}
+				if want := passwordDigest(nonce, token.Created, "secret"); token.Password.Value != want {
+					t.Errorf("Password = %v, want digest %v", token.Password.Value, want)
+				}
+			}
+			if (security.Timestamp != nil) != tt.wantTimestamp {
+				t.Fatalf("Timestamp = %+v, want %v", security.Timestamp, tt.wantTimestamp)
+			}
+			if tt.wantTimestamp && security.Timestamp.Expires != "2019-11-19T10:35:00.000Z" {
+				t.Errorf("Expires = %v, want 2019-11-19T10:35:00.000Z", security.Timestamp.Expires)
+			}
+		})
+	}
+}
+
+func Test_passwordDigest(t *testing.T) {
+	// Example of the UsernameToken profile interoperability scenarios.
+	nonce, _ := base64.StdEncoding.DecodeString("LKqI6G/AikKCQrN0zqZFlg==")
+	got := passwordDigest(nonce, "2010-09-16T07:50:45Z", "userpassword")
+	if want := "tuOSpGlFlIXsozq4HFNeeGeFLEI="; got != want {
+		t.Errorf("passwordDigest() = %v, want %v", got, want)
+	}
+}
+
+func Test_insertHeader(t *testing.T) {
+	const entry = `<Token>abc</Token>`
+	tests := []struct {
+		name     string
+		envelope string
+		want     string
+	}{
+		{
+			name:     "Add Header before Body",
+			envelope: `<soap:Envelope xmlns:soap="urn:soap"><soap:Body><Op/></soap:Body></soap:Envelope>`,
+			want:     `<soap:Envelope xmlns:soap="urn:soap"><soap:Header><Token>abc</Token></soap:Header><soap:Body><Op/></soap:Body></soap:Envelope>`,
+		},
+		{
+			name:     "Prepend to Header",
+			envelope: `<s:Envelope xmlns:s="urn:soap"><s:Header><Other/></s:Header><s:Body/></s:Envelope>`,
+			want:     `<s:Envelope xmlns:s="urn:soap"><s:Header><Token>abc</Token><Other/></s:Header><s:Body/></s:Envelope>`,
+		},
+		{
+			name:     "Fill empty Header",
+			envelope: `<soapenv:Envelope xmlns:soapenv="urn:soap"><soapenv:Header/><soapenv:Body/></soapenv:Envelope>`,
+			want:     `<soapenv:Envelope xmlns:soapenv="urn:soap"><soapenv:Header><Token>abc</Token></soapenv:Header><soapenv:Body/></soapenv:Envelope>`,
+		},
+		{
+			name:     "Default namespace",
+			envelope: `<Envelope xmlns="urn:soap"><Body/></Envelope>`,
+			want:     `<Envelope xmlns="urn:soap"><Header><Token>abc</Token></Header><Body/></Envelope>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := insertHeader([]byte(tt.envelope), []byte(entry))
+			if err != nil {
+				t.Fatalf("insertHeader() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("insertHeader() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
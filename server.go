@@ -0,0 +1,287 @@
+package soap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Server struct is an http.Handler exposing Go functions as SOAP operations.
+//
+// The incoming envelope is dispatched by its SOAPAction, or by the name of
+// its Body element when no operation is registered for the action. The
+// payload is decoded in the input struct of the function, and the returned
+// struct is sent back in an envelope of the request SOAP version.
+//
+//	server := soap.NewServer().
+//		Handle("http://www.dataaccess.com/webservicesserver/NumberToWords", numberToWords)
+//	http.ListenAndServe(":8080", server)
+type Server struct {
+	// MaxRequestSize is the maximum size in bytes of the request bodies,
+	// DefaultMaxRequestSize when zero, a negative size meaning no limit.
+	MaxRequestSize int64
+	operations     []*operation
+}
+
+// DefaultMaxRequestSize is the maximum size of the request bodies read by a
+// Server without MaxRequestSize.
+const DefaultMaxRequestSize = 10 << 20
+
+// operation is a function registered on the Server.
+type operation struct {
+	action  string
+	element xml.Name
+	fn      reflect.Value
+	in      reflect.Type
+	context bool
+	output  bool
+}
+
+// NewServer method creates a new SOAP server without operations.
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Handle method registers a function for the SOAP action and for the Body
+// element of its input struct, declared by its XMLName field tag or by its
+// type name. The function has one of the signatures
+//
+//	func(ctx context.Context, request *In) (*Out, error)
+//	func(request *In) (*Out, error)
+//	func(ctx context.Context, request *In) error
+//	func(request *In) error
+//
+// where In and Out are the same body-only or envelope structs used with
+// Request. A *Fault error is sent as is, any other error is sent as a
+// server fault with the error message as reason. Handle panics when the
+// function does not have one of these signatures.
+func (s *Server) Handle(action string, fn interface{}) *Server {
+	op, err := newOperation(action, fn)
+	if err != nil {
+		panic(err)
+	}
+	s.operations = append(s.operations, op)
+	return s
+}
+
+func newOperation(action string, fn interface{}) (*operation, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("soap: handler of %q is not a function", action)
+	}
+	t := v.Type()
+	op := &operation{action: action, fn: v}
+	in := 0
+	if t.NumIn() == 2 && t.In(0) == contextType {
+		op.context = true
+		in = 1
+	}
+	if t.NumIn() != in+1 || t.In(in).Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("soap: handler of %q must take a pointer to its request struct", action)
+	}
+	switch {
+	case t.NumOut() == 1 && t.Out(0) == errorType:
+	case t.NumOut() == 2 && t.Out(1) == errorType && t.Out(0).Kind() == reflect.Ptr:
+		op.output = true
+	default:
+		return nil, fmt.Errorf("soap: handler of %q must return a pointer to its response struct and an error", action)
+	}
+	op.in = t.In(in).Elem()
+
+	if !isEnvelope(reflect.New(op.in).Interface()) {
+		op.element = xml.Name{Local: op.in.Name()}
+		if f, ok := op.in.FieldByName("XMLName"); ok {
+			tag := f.Tag.Get("xml")
+			if i := strings.Index(tag, ","); i >= 0 {
+				tag = tag[:i]
+			}
+			if i := strings.LastIndex(tag, " "); i >= 0 {
+				op.element.Space = tag[:i]
+			}
+			if name := localName(tag); name != "" {
+				op.element.Local = name
+			}
+		}
+	}
+	return op, nil
+}
+
+// SetMaxRequestSize method sets the maximum size in bytes of the request
+// bodies, a larger request being answered with a Client fault.
+//		server := soap.NewServer().
+//			SetMaxRequestSize(1 << 20)
+func (s *Server) SetMaxRequestSize(size int64) *Server {
+	s.MaxRequestSize = size
+	return s
+}
+
+// ServeHTTP method decodes the SOAP request, calls the operation and writes
+// its response or fault.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	version, action := requestVersion(r)
+	max := s.maxRequestSize()
+	if max > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, max)
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		// The body is cut after max bytes when it is larger.
+		if max > 0 && int64(len(data)) == max {
+			err = fmt.Errorf("soap: request body larger than %d bytes", max)
+		}
+		s.fault(w, version, &Fault{Code: "Client", Reason: err.Error()})
+		return
+	}
+	element, namespace, err := bodyName(data)
+	if err != nil {
+		s.fault(w, version, &Fault{Code: "Client", Reason: err.Error()})
+		return
+	}
+	if v, ok := versionOf(namespace); ok {
+		version = v
+	} else {
+		s.fault(w, version, &Fault{Code: "VersionMismatch", Reason: fmt.Sprintf("soap: unknown envelope namespace %q", namespace)})
+		return
+	}
+
+	op := s.operation(action, element)
+	if op == nil {
+		s.fault(w, version, &Fault{Code: "Client", Reason: fmt.Sprintf("soap: no operation for action %q and element %s", action, element.Local)})
+		return
+	}
+	in := reflect.New(op.in)
+	if err := unmarshalEnvelope(data, in.Interface()); err != nil {
+		s.fault(w, version, &Fault{Code: "Client", Reason: err.Error()})
+		return
+	}
+
+	args := []reflect.Value{in}
+	if op.context {
+		args = []reflect.Value{reflect.ValueOf(r.Context()), in}
+	}
+	out := op.fn.Call(args)
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		var fault *Fault
+		if !errors.As(err, &fault) {
+			fault = &Fault{Code: "Server", Reason: err.Error()}
+		}
+		s.fault(w, version, fault)
+		return
+	}
+	if !op.output {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	var payload interface{}
+	if !out[0].IsNil() {
+		payload = out[0].Interface()
+	}
+	body, err := marshalEnvelope(version, payload)
+	if err != nil {
+		s.fault(w, version, &Fault{Code: "Server", Reason: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", version.ContentType(""))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// operation returns the operation registered for the action, or else for
+// the Body element, nil if none.
+func (s *Server) operation(action string, element xml.Name) *operation {
+	if action != "" {
+		for _, op := range s.operations {
+			if op.action == action {
+				return op
+			}
+		}
+	}
+	for _, op := range s.operations {
+		if op.element.Local == element.Local && (op.element.Space == "" || op.element.Space == element.Space) {
+			return op
+		}
+	}
+	return nil
+}
+
+// maxRequestSize returns the maximum size of the request bodies, zero
+// meaning no limit.
+func (s *Server) maxRequestSize() int64 {
+	switch {
+	case s.MaxRequestSize < 0:
+		return 0
+	case s.MaxRequestSize == 0:
+		return DefaultMaxRequestSize
+	}
+	return s.MaxRequestSize
+}
+
+// fault writes the fault with status 500, or 400 for SOAP 1.2 sender faults.
+func (s *Server) fault(w http.ResponseWriter, version SOAPVersion, fault *Fault) {
+	body, err := marshalFault(version, fault)
+	if err != nil {
+		body, _ = marshalFault(version, &Fault{Code: "Server", Reason: err.Error()})
+	}
+	status := http.StatusInternalServerError
+	if version == SOAP12 && fault.envelopeCode(version) == "soap:Sender" {
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", version.ContentType(""))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// requestVersion returns the SOAP version and the action declared by the
+// HTTP headers of the request.
+func requestVersion(r *http.Request) (SOAPVersion, string) {
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/soap+xml" {
+		return SOAP12, params["action"]
+	}
+	return SOAP11, strings.Trim(r.Header.Get("SOAPAction"), `"`)
+}
+
+// bodyName returns the name of the first Body element and the namespace of
+// the envelope.
+func bodyName(data []byte) (xml.Name, string, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var root xml.StartElement
+	for root.Name.Local == "" {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.Name{}, "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			root = start
+		}
+	}
+	if root.Name.Local != "Envelope" {
+		return xml.Name{}, "", fmt.Errorf("soap: root element %s is not an Envelope", root.Name.Local)
+	}
+	namespace := root.Name.Space
+	d = xml.NewDecoder(bytes.NewReader(data))
+	start, err := bodyElement(d)
+	if err != nil || start == nil {
+		return xml.Name{}, namespace, err
+	}
+	return start.Name, namespace, nil
+}
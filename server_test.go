@@ -0,0 +1,240 @@
+package soap
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const numberToWordsAction = "http://www.dataaccess.com/webservicesserver/NumberToWords"
+
+func numberToWordsHandler(ctx context.Context, request *NumberToWords) (*NumberToWordsResponse, error) {
+	switch request.UbiNum {
+	case "-1":
+		return nil, &Fault{Code: "soap:Client", Reason: "Invalid number", DetailValue: &InvalidNumber{Number: request.UbiNum}}
+	case "0":
+		return nil, errors.New("zero is not supported")
+	}
+	return &NumberToWordsResponse{NumberToWordsResult: "words of " + request.UbiNum}, nil
+}
+
+func createTestServer() *httptest.Server {
+	return httptest.NewServer(NewServer().
+		SetMaxRequestSize(4096).
+		Handle(numberToWordsAction, numberToWordsHandler).
+		Handle("", func(request *Ping) error { return nil }))
+}
+
+type Ping struct {
+	XMLName xml.Name `xml:"urn:test Ping"`
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	server := createTestServer()
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		version    SOAPVersion
+		action     string
+		ubiNum     string
+		wantResult string
+		wantCode   string
+		wantDetail string
+	}{
+		{name: "SOAP 1.1 by action", version: SOAP11, action: numberToWordsAction, ubiNum: "7", wantResult: "words of 7"},
+		{name: "SOAP 1.2 by action", version: SOAP12, action: numberToWordsAction, ubiNum: "8", wantResult: "words of 8"},
+		{name: "By body element", version: SOAP11, ubiNum: "9", wantResult: "words of 9"},
+		{name: "Unknown action falls back to body element", version: SOAP12, action: "urn:unknown", ubiNum: "10", wantResult: "words of 10"},
+		{name: "SOAP 1.1 fault", version: SOAP11, action: numberToWordsAction, ubiNum: "-1", wantCode: "soap:Client", wantDetail: "-1"},
+		{name: "SOAP 1.2 fault", version: SOAP12, action: numberToWordsAction, ubiNum: "-1", wantCode: "soap:Sender", wantDetail: "-1"},
+		{name: "Error as server fault", version: SOAP12, action: numberToWordsAction, ubiNum: "0", wantCode: "soap:Receiver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &NumberToWordsResponse{}
+			_, err := New().R().
+				SetUrl(server.URL).
+				SetSOAPVersion(tt.version).
+				SetSOAPAction(tt.action).
+				SetPayloadRequest(&NumberToWords{UbiNum: tt.ubiNum}).
+				SetPayloadResponse(response).
+				SetFaultDetail(&InvalidNumber{}).
+				Call()
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Call() error = %v", err)
+				}
+				if response.NumberToWordsResult != tt.wantResult {
+					t.Errorf("NumberToWordsResult = %v, want %v", response.NumberToWordsResult, tt.wantResult)
+				}
+				return
+			}
+			var fault *Fault
+			if !errors.As(err, &fault) {
+				t.Fatalf("Call() error = %v, want *Fault", err)
+			}
+			if fault.Version != tt.version || fault.Code != tt.wantCode {
+				t.Errorf("Fault = %v %v, want %v %v", fault.Version, fault.Code, tt.version, tt.wantCode)
+			}
+			if tt.wantDetail != "" {
+				detail, _ := fault.DetailValue.(*InvalidNumber)
+				if detail == nil || detail.Number != tt.wantDetail {
+					t.Errorf("DetailValue = %v, want number %v", fault.DetailValue, tt.wantDetail)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP_OneWay(t *testing.T) {
+	server := createTestServer()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		version   SOAPVersion
+		streaming bool
+		response  interface{}
+		wantErr   error
+	}{
+		{name: "SOAP 1.1", version: SOAP11},
+		{name: "SOAP 1.2", version: SOAP12},
+		{name: "Streamed", version: SOAP11, streaming: true},
+		{name: "Payload response expected", version: SOAP11, response: &NumberToWordsResponse{}, wantErr: ErrStatus},
+		{name: "Streamed payload response expected", version: SOAP11, streaming: true, response: &NumberToWordsResponse{}, wantErr: ErrStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := New().R().
+				SetUrl(server.URL).
+				SetSOAPVersion(tt.version).
+				SetStreaming(tt.streaming).
+				SetPayloadRequest(&Ping{})
+			if tt.response != nil {
+				req.SetPayloadResponse(tt.response)
+			}
+			resp, err := req.Call()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Call() error = %v, want %v", err, tt.wantErr)
+			}
+			if resp.StatusCode() != http.StatusAccepted {
+				t.Errorf("StatusCode() = %v, want %v", resp.StatusCode(), http.StatusAccepted)
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP_Errors(t *testing.T) {
+	server := createTestServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "Method not allowed",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:        "Not an envelope",
+			method:      http.MethodPost,
+			contentType: "text/xml",
+			body:        `<NumberToWords/>`,
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    "<faultcode>soap:Client</faultcode>",
+		},
+		{
+			name:        "Unknown envelope namespace",
+			method:      http.MethodPost,
+			contentType: "text/xml",
+			body:        `<Envelope xmlns="urn:unknown"><Body/></Envelope>`,
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    "<faultcode>soap:VersionMismatch</faultcode>",
+		},
+		{
+			name:        "Unknown operation",
+			method:      http.MethodPost,
+			contentType: "application/soap+xml",
+			body:        `<e:Envelope xmlns:e="http://www.w3.org/2003/05/soap-envelope"><e:Body><Unknown/></e:Body></e:Envelope>`,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    "<soap:Value>soap:Sender</soap:Value>",
+		},
+		{
+			name:        "Request too large",
+			method:      http.MethodPost,
+			contentType: "text/xml",
+			body:        `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body><NumberToWords xmlns="http://www.dataaccess.com/webservicesserver/"><ubiNum>` + strings.Repeat("7", 4096) + `</ubiNum></NumberToWords></soapenv:Body></soapenv:Envelope>`,
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    "<faultstring>soap: request body larger than 4096 bytes</faultstring>",
+		},
+		{
+			name:        "SOAP 1.2 request too large",
+			method:      http.MethodPost,
+			contentType: "application/soap+xml",
+			body:        `<e:Envelope xmlns:e="http://www.w3.org/2003/05/soap-envelope"><e:Body>` + strings.Repeat(" ", 4096) + `</e:Body></e:Envelope>`,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    "<soap:Value>soap:Sender</soap:Value>",
+		},
+		{
+			name:        "One-way operation",
+			method:      http.MethodPost,
+			contentType: "text/xml",
+			body:        `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body><Ping xmlns="urn:test"/></soapenv:Body></soapenv:Envelope>`,
+			wantStatus:  http.StatusAccepted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			body, _ := ioutil.ReadAll(resp.Body)
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("Body = %s, want %v", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_Handle(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        interface{}
+		wantPanic bool
+	}{
+		{name: "Context and response", fn: numberToWordsHandler},
+		{name: "Request only", fn: func(*NumberToWords) error { return nil }},
+		{name: "Not a function", fn: "handler", wantPanic: true},
+		{name: "Request not a pointer", fn: func(NumberToWords) error { return nil }, wantPanic: true},
+		{name: "No error result", fn: func(*NumberToWords) *NumberToWordsResponse { return nil }, wantPanic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("Handle() panic = %v, wantPanic %v", r, tt.wantPanic)
+				}
+			}()
+			NewServer().Handle("action", tt.fn)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package soap
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	// Register the hash functions of the supported algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// DSigNamespace is the XML digital signature namespace.
+const DSigNamespace = "http://www.w3.org/2000/09/xmldsig#"
+
+// Signature algorithms of the XML digital signature.
+const (
+	RSASHA1     = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	RSASHA256   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	RSASHA512   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	ECDSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	ECDSASHA512 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512"
+)
+
+// Digest algorithms of the XML digital signature references.
+const (
+	DigestSHA1   = "http://www.w3.org/2000/09/xmldsig#sha1"
+	DigestSHA256 = "http://www.w3.org/2001/04/xmlenc#sha256"
+	DigestSHA512 = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// URIs of the WS-Security X.509 token profile.
+const (
+	X509TokenURI            = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-x509-token-profile-1.0#X509v3"
+	SubjectKeyIdentifierURI = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-x509-token-profile-1.0#X509SubjectKeyIdentifier"
+)
+
+var signatureMethods = map[string]struct {
+	hash  crypto.Hash
+	ecdsa bool
+}{
+	RSASHA1:     {crypto.SHA1, false},
+	RSASHA256:   {crypto.SHA256, false},
+	RSASHA512:   {crypto.SHA512, false},
+	ECDSASHA256: {crypto.SHA256, true},
+	ECDSASHA512: {crypto.SHA512, true},
+}
+
+var digestMethods = map[string]crypto.Hash{
+	DigestSHA1:   crypto.SHA1,
+	DigestSHA256: crypto.SHA256,
+	DigestSHA512: crypto.SHA512,
+}
+
+// KeyReference type is the way the signature refers to the certificate.
+type KeyReference int
+
+const (
+	// BinarySecurityTokenReference sends the certificate in a
+	// `wsse:BinarySecurityToken` referenced by the signature.
+	BinarySecurityTokenReference KeyReference = iota
+	// KeyIdentifierReference refers to the certificate by its X.509 subject
+	// key identifier, known by the service.
+	KeyIdentifierReference
+)
+
+// Signer struct configures the XML digital signature of the request
+// envelope. The Body, the Timestamp and the header entries named in Parts
+// are signed with exclusive canonicalization.
+//
+//	client.SetWSSecurity(&soap.WSSecurity{
+//		TimestampTTL: 5 * time.Minute,
+//		Signer: &soap.Signer{
+//			Certificate: cert,
+//			PrivateKey:  key,
+//		},
+//	})
+type Signer struct {
+	Certificate *x509.Certificate
+	PrivateKey  crypto.Signer
+	// SignatureAlgorithm defaults to RSASHA256 or ECDSASHA256 depending on
+	// the key.
+	SignatureAlgorithm string
+	// DigestAlgorithm defaults to DigestSHA256.
+	DigestAlgorithm string
+	KeyReference    KeyReference
+	// Parts are the header entries signed along with the Body and the
+	// Timestamp.
+	Parts []xml.Name
+}
+
+type binarySecurityToken struct {
+	ID           string `xml:"wsu:Id,attr"`
+	EncodingType string `xml:"EncodingType,attr"`
+	ValueType    string `xml:"ValueType,attr"`
+	Value        string `xml:",chardata"`
+}
+
+type signatureElement struct {
+	XMLName    xml.Name          `xml:"ds:Signature"`
+	Ds         string            `xml:"xmlns:ds,attr"`
+	SignedInfo signedInfoElement `xml:"ds:SignedInfo"`
+	Value      string            `xml:"ds:SignatureValue"`
+	KeyInfo    tokenReference    `xml:"ds:KeyInfo>wsse:SecurityTokenReference"`
+}
+
+type tokenReference struct {
+	Reference     *tokenURI        `xml:"wsse:Reference,omitempty"`
+	KeyIdentifier *securityValue   `xml:"wsse:KeyIdentifier,omitempty"`
+	X509Data      *x509DataElement `xml:"ds:X509Data,omitempty"`
+}
+
+type tokenURI struct {
+	URI       string `xml:"URI,attr"`
+	ValueType string `xml:"ValueType,attr"`
+}
+
+type signedInfoElement struct {
+	CanonicalizationMethod algorithmElement   `xml:"ds:CanonicalizationMethod"`
+	SignatureMethod        algorithmElement   `xml:"ds:SignatureMethod"`
+	References             []referenceElement `xml:"ds:Reference"`
+}
+
+type referenceElement struct {
+	URI          string             `xml:"URI,attr"`
+	Transforms   []algorithmElement `xml:"ds:Transforms>ds:Transform"`
+	DigestMethod algorithmElement   `xml:"ds:DigestMethod"`
+	DigestValue  string             `xml:"ds:DigestValue"`
+}
+
+type algorithmElement struct {
+	Algorithm string `xml:"Algorithm,attr"`
+}
+
+// algorithms returns the signature and digest algorithms, with their defaults.
+func (s *Signer) algorithms() (string, string, error) {
+	if s.Certificate == nil || s.PrivateKey == nil {
+		return "", "", errors.New("soap: signer needs a certificate and a private key")
+	}
+	signature, digest := s.SignatureAlgorithm, s.DigestAlgorithm
+	if signature == "" {
+		switch s.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			signature = RSASHA256
+		case *ecdsa.PublicKey:
+			signature = ECDSASHA256
+		default:
+			return "", "", fmt.Errorf("soap: unsupported signing key %T", s.PrivateKey.Public())
+		}
+	}
+	if _, ok := signatureMethods[signature]; !ok {
+		return "", "", fmt.Errorf("soap: unsupported signature algorithm %q", signature)
+	}
+	if digest == "" {
+		digest = DigestSHA256
+	}
+	if _, ok := digestMethods[digest]; !ok {
+		return "", "", fmt.Errorf("soap: unsupported digest algorithm %q", digest)
+	}
+	return signature, digest, nil
+}
+
+// token returns the `wsse:BinarySecurityToken` of the certificate, nil
+// when the signature refers to it by key identifier.
+func (s *Signer) token() *binarySecurityToken {
+	if s.KeyReference != BinarySecurityTokenReference || s.Certificate == nil {
+		return nil
+	}
+	return &binarySecurityToken{
+		ID:           "X509-1",
+		EncodingType: Base64BinaryURI,
+		ValueType:    X509TokenURI,
+		Value:        base64.StdEncoding.EncodeToString(s.Certificate.Raw),
+	}
+}
+
+// sign appends the signature of the envelope parts to the security header.
+func (s *Signer) sign(envelope, security *xmlElement) error {
+	signatureMethod, digestMethod, err := s.algorithms()
+	if err != nil {
+		return err
+	}
+
+	namespace := envelope.namespace()
+	targets := []*xmlElement{envelope.child(namespace, "Body")}
+	if targets[0] == nil {
+		return errBodyNotFound
+	}
+	if timestamp := security.child(WSUNamespace, "Timestamp"); timestamp != nil {
+		targets = append(targets, timestamp)
+	}
+	if header := envelope.child(namespace, "Header"); header != nil {
+		for _, part := range s.Parts {
+			for _, entry := range header.elements() {
+				if entry.is(part.Space, part.Local) {
+					targets = append(targets, entry)
+				}
+			}
+		}
+	}
+
+	signature := signatureElement{Ds: DSigNamespace}
+	signature.SignedInfo.CanonicalizationMethod.Algorithm = ExcC14N
+	signature.SignedInfo.SignatureMethod.Algorithm = signatureMethod
+	for i, target := range targets {
+		id, ok := target.attr(WSUNamespace, "Id")
+		if !ok {
+			id = "id-" + strconv.Itoa(i+1)
+			target.setAttr(target.declare("wsu", WSUNamespace), "Id", id)
+		}
+		h := digestMethods[digestMethod].New()
+		h.Write(canonicalize(target, nil))
+		signature.SignedInfo.References = append(signature.SignedInfo.References, referenceElement{
+			URI:          "#" + id,
+			Transforms:   []algorithmElement{{Algorithm: ExcC14N}},
+			DigestMethod: algorithmElement{Algorithm: digestMethod},
+			DigestValue:  base64.StdEncoding.EncodeToString(h.Sum(nil)),
+		})
+	}
+
+	ref := &signature.KeyInfo
+	if token := s.token(); token != nil {
+		ref.Reference = &tokenURI{URI: "#" + token.ID, ValueType: X509TokenURI}
+	} else {
+		if len(s.Certificate.SubjectKeyId) == 0 {
+			return errors.New("soap: certificate has no subject key identifier")
+		}
+		ref.KeyIdentifier = &securityValue{
+			EncodingType: Base64BinaryURI,
+			ValueType:    SubjectKeyIdentifierURI,
+			Value:        base64.StdEncoding.EncodeToString(s.Certificate.SubjectKeyId),
+		}
+	}
+
+	data, err := xml.Marshal(&signature)
+	if err != nil {
+		return err
+	}
+	element, err := parseXML(data)
+	if err != nil {
+		return err
+	}
+	security.appendChild(element)
+
+	// SignedInfo is canonicalized in the context of the document.
+	signedInfo := element.child(DSigNamespace, "SignedInfo")
+	value, err := s.signatureValue(signatureMethod, canonicalize(signedInfo, nil))
+	if err != nil {
+		return err
+	}
+	signatureValue := element.child(DSigNamespace, "SignatureValue")
+	signatureValue.children = []interface{}{xmlText(base64.StdEncoding.EncodeToString(value))}
+	return nil
+}
+
+// signatureValue signs the canonical SignedInfo. ECDSA signatures are
+// encoded as the concatenation of r and s required by XML-DSig.
+func (s *Signer) signatureValue(method string, signedInfo []byte) ([]byte, error) {
+	m := signatureMethods[method]
+	h := m.hash.New()
+	h.Write(signedInfo)
+	value, err := s.PrivateKey.Sign(rand.Reader, h.Sum(nil), m.hash)
+	if err != nil || !m.ecdsa {
+		return value, err
+	}
+	key, ok := s.PrivateKey.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("soap: %s needs an ECDSA key", method)
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(value, &sig); err != nil {
+		return nil, err
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	raw := make([]byte, 2*size)
+	r, v := sig.R.Bytes(), sig.S.Bytes()
+	copy(raw[size-len(r):size], r)
+	copy(raw[2*size-len(v):], v)
+	return raw, nil
+}
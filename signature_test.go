@@ -0,0 +1,226 @@
+package soap
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCertificate returns a self-signed certificate of the key.
+func newTestCertificate(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "soap test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		SubjectKeyId: []byte{1, 2, 3, 4},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// checkSignature verifies the references and the signature value of the
+// signed envelope and returns the signed elements.
+func checkSignature(t *testing.T, data []byte, cert *x509.Certificate) []*xmlElement {
+	t.Helper()
+	root, err := parseXML(data)
+	if err != nil {
+		t.Fatalf("parseXML() error = %v", err)
+	}
+	signature := root.find(func(e *xmlElement) bool { return e.is(DSigNamespace, "Signature") })
+	if signature == nil {
+		t.Fatalf("no Signature in %s", data)
+	}
+	signedInfo := signature.child(DSigNamespace, "SignedInfo")
+
+	var signed []*xmlElement
+	for _, ref := range signedInfo.elements() {
+		if ref.local != "Reference" {
+			continue
+		}
+		uri, _ := ref.attr("", "URI")
+		target := root.find(func(e *xmlElement) bool {
+			id, ok := e.attr(WSUNamespace, "Id")
+			return ok && "#"+id == uri
+		})
+		if target == nil {
+			t.Fatalf("Reference %s not found", uri)
+		}
+		method, _ := ref.child(DSigNamespace, "DigestMethod").attr("", "Algorithm")
+		h := digestMethods[method].New()
+		h.Write(canonicalize(target, nil))
+		if got, want := ref.child(DSigNamespace, "DigestValue").text(), base64.StdEncoding.EncodeToString(h.Sum(nil)); got != want {
+			t.Errorf("DigestValue of %s = %v, want %v", uri, got, want)
+		}
+		signed = append(signed, target)
+	}
+
+	method, _ := signedInfo.child(DSigNamespace, "SignatureMethod").attr("", "Algorithm")
+	m := signatureMethods[method]
+	h := m.hash.New()
+	h.Write(canonicalize(signedInfo, nil))
+	value, _ := base64.StdEncoding.DecodeString(signature.child(DSigNamespace, "SignatureValue").text())
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, m.hash, h.Sum(nil), value); err != nil {
+			t.Errorf("VerifyPKCS1v15() error = %v", err)
+		}
+	case *ecdsa.PublicKey:
+		size := len(value) / 2
+		r, s := new(big.Int).SetBytes(value[:size]), new(big.Int).SetBytes(value[size:])
+		if !ecdsa.Verify(key, h.Sum(nil), r, s) {
+			t.Errorf("ecdsa.Verify() = false")
+		}
+	}
+	return signed
+}
+
+func TestRequest_Call_Signer(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaCert, ecCert := newTestCertificate(t, rsaKey), newTestCertificate(t, ecKey)
+
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		version       SOAPVersion
+		signer        *Signer
+		timestampTTL  time.Duration
+		payload       interface{}
+		wantSigned    []string
+		wantAlgorithm string
+		wantKeyRef    string
+	}{
+		{
+			name:          "RSA with binary security token",
+			version:       SOAP11,
+			signer:        &Signer{Certificate: rsaCert, PrivateKey: rsaKey},
+			timestampTTL:  time.Minute,
+			payload:       &NumberToWords{UbiNum: "7"},
+			wantSigned:    []string{"Body", "Timestamp"},
+			wantAlgorithm: RSASHA256,
+			wantKeyRef:    "<wsse:Reference URI=\"#X509-1\"",
+		},
+		{
+			name:          "ECDSA with key identifier",
+			version:       SOAP12,
+			signer:        &Signer{Certificate: ecCert, PrivateKey: ecKey, KeyReference: KeyIdentifierReference, DigestAlgorithm: DigestSHA512},
+			payload:       &NumberToWords{UbiNum: "8"},
+			wantSigned:    []string{"Body"},
+			wantAlgorithm: ECDSASHA256,
+			wantKeyRef:    "<wsse:KeyIdentifier EncodingType=\"" + Base64BinaryURI + "\" ValueType=\"" + SubjectKeyIdentifierURI + "\">AQIDBA==</wsse:KeyIdentifier>",
+		},
+		{
+			name:    "Header part of an envelope payload",
+			version: SOAP11,
+			signer: &Signer{
+				Certificate:        rsaCert,
+				PrivateKey:         rsaKey,
+				SignatureAlgorithm: RSASHA1,
+				Parts:              []xml.Name{{Local: "Token"}},
+			},
+			payload:       NewEnvelope(&NumberToWords{UbiNum: "9"}).AddHeader(&DummyHeader{Token: "abc"}),
+			wantSigned:    []string{"Body", "Token"},
+			wantAlgorithm: RSASHA1,
+			wantKeyRef:    "<wsse:Reference URI=\"#X509-1\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().R().
+				SetUrl(server.URL).
+				SetSOAPVersion(tt.version).
+				SetWSSecurity(&WSSecurity{Signer: tt.signer, TimestampTTL: tt.timestampTTL}).
+				SetPayloadRequest(tt.payload).
+				Call()
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+
+			signed := checkSignature(t, received, tt.signer.Certificate)
+			var names []string
+			for _, e := range signed {
+				names = append(names, e.local)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantSigned, ",") {
+				t.Errorf("signed = %v, want %v", names, tt.wantSigned)
+			}
+			if !strings.Contains(string(received), `<ds:SignatureMethod Algorithm="`+tt.wantAlgorithm+`">`) {
+				t.Errorf("SignatureMethod of %s, want %v", received, tt.wantAlgorithm)
+			}
+			if !strings.Contains(string(received), tt.wantKeyRef) {
+				t.Errorf("KeyInfo of %s, want %v", received, tt.wantKeyRef)
+			}
+
+			response := &NumberToWords{}
+			if err := unmarshalEnvelope(received, response); err != nil || response.UbiNum == "" {
+				t.Errorf("unmarshalEnvelope() = %v, %v, want the signed payload", response, err)
+			}
+		})
+	}
+}
+
+func TestSigner_algorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestCertificate(t, rsaKey)
+
+	tests := []struct {
+		name          string
+		signer        *Signer
+		wantSignature string
+		wantDigest    string
+		wantErr       bool
+	}{
+		{name: "Defaults", signer: &Signer{Certificate: cert, PrivateKey: rsaKey}, wantSignature: RSASHA256, wantDigest: DigestSHA256},
+		{name: "Missing key", signer: &Signer{Certificate: cert}, wantErr: true},
+		{name: "Unknown signature algorithm", signer: &Signer{Certificate: cert, PrivateKey: rsaKey, SignatureAlgorithm: "urn:unknown"}, wantErr: true},
+		{name: "Unknown digest algorithm", signer: &Signer{Certificate: cert, PrivateKey: rsaKey, DigestAlgorithm: "urn:unknown"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, digest, err := tt.signer.algorithms()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("algorithms() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if signature != tt.wantSignature || digest != tt.wantDigest {
+				t.Errorf("algorithms() = %v, %v, want %v, %v", signature, digest, tt.wantSignature, tt.wantDigest)
+			}
+		})
+	}
+}
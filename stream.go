@@ -0,0 +1,154 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sync/atomic"
+)
+
+// limitedReader reads at most remaining bytes and fails with
+// ErrResponseTooLarge past them.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrResponseTooLarge
+	}
+	return n, err
+}
+
+// countingReader adds the number of bytes read to n.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+// Close closes the underlying reader, so that the transport closing a
+// streamed request body unblocks the encoding of its envelope.
+func (c *countingReader) Close() error {
+	if closer, ok := c.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// captureReader copies what is read into buf until it is stopped.
+type captureReader struct {
+	r   io.Reader
+	buf *bytes.Buffer
+}
+
+func (c *captureReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if c.buf != nil {
+		c.buf.Write(p[:n])
+	}
+	return n, err
+}
+
+// streamsRequest reports whether the envelope is encoded while it is sent,
+// unless WS-Addressing, WS-Security, MTOM, attachments or before-send hooks
+// need it whole.
+func (r *Request) streamsRequest() bool {
+	if !r.streaming() || r.addressing() != nil || r.mtom() || len(r.Attachments) > 0 || len(r.beforeSendHooks()) > 0 {
+		return false
+	}
+	security := r.wsSecurity()
+	return security == nil || !security.sendsHeader()
+}
+
+// streamsResponse reports whether the response is decoded while it is
+// received, when streamed or deferred, unless its decryption, its
+// signature, its parts, an envelope payload or after-receive hooks need it
+// whole.
+func (r *Request) streamsResponse(resp *http.Response) bool {
+	if !(r.streaming() || r.DeferDecoding) || isEnvelope(r.PayloadResponse) || isEnvelope(r.PayloadFault) || len(r.afterReceiveHooks()) > 0 {
+		return false
+	}
+	if security := r.wsSecurity(); security != nil && (security.Verifier != nil || security.DecryptionKey != nil) {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType != "multipart/related"
+}
+
+// decodeStream decodes the payload response, or the payload fault, from
+// the body as it is read. The envelope up to the Body content is kept to
+// parse a SOAP Fault, the payload itself is not held in the response. With
+// deferred decoding, the Body is left to the Response.Elements iteration.
+func (r *Request) decodeStream(response *Response, body io.Reader) (*Response, error) {
+	head := &captureReader{r: body, buf: &bytes.Buffer{}}
+	d := xml.NewDecoder(head)
+	start, err := bodyElement(d)
+	if err != nil {
+		if errors.Is(err, errBodyNotFound) && r.emptyReply(response, head.buf.Bytes()) {
+			return response, nil
+		}
+		// A non 200 response without envelope, such as an error page, is a
+		// status error as when it is read whole.
+		if response.StatusCode() != http.StatusOK && !errors.Is(err, ErrResponseTooLarge) {
+			return response, newError(ErrStatus, errors.New(response.Status()))
+		}
+		return response, streamError(err)
+	}
+	if start != nil && start.Name.Local == "Fault" {
+		if _, ok := versionOf(start.Name.Space); ok {
+			// Faults are small, they are read whole.
+			if _, err := io.Copy(ioutil.Discard, head); err != nil {
+				return response, newError(ErrRead, err)
+			}
+			response.payloadResponse = head.buf.Bytes()
+			return r.decode(response)
+		}
+	}
+	head.buf = nil
+
+	if response.StatusCode() != http.StatusOK {
+		if r.PayloadFault != nil && start != nil {
+			_ = d.DecodeElement(r.PayloadFault, start)
+		}
+		return response, newError(ErrStatus, errors.New(response.Status()))
+	}
+	if r.DeferDecoding {
+		response.stream = &bodyStream{d: d, start: start, body: response.RawResponse.Body}
+		return response, nil
+	}
+	if r.PayloadResponse == nil || start == nil {
+		return response, nil
+	}
+	if err := d.DecodeElement(r.PayloadResponse, start); err != nil {
+		return response, streamError(err)
+	}
+	return response, nil
+}
+
+// streamError returns the error of a streamed decoding, a read error when
+// the body could not be read.
+func streamError(err error) error {
+	if errors.Is(err, ErrResponseTooLarge) {
+		return newError(ErrRead, err)
+	}
+	return newError(ErrDecode, err)
+}
@@ -0,0 +1,212 @@
+package soap
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type Report struct {
+	XMLName xml.Name `xml:"urn:test Report"`
+	Rows    []string `xml:"Row"`
+}
+
+func TestRequest_Call_Streaming(t *testing.T) {
+	rows := strings.Repeat("<Row>row</Row>", 10000)
+	report := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Header/><soap:Body><Report xmlns="urn:test">` + rows + `</Report></soap:Body></soap:Envelope>`
+	fault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>boom</faultstring></soap:Fault></soap:Body></soap:Envelope>`
+
+	var chunked bool
+	var request []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunked = r.ContentLength == -1
+		request, _ = ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/fault":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fault))
+		case "/invalid":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><InvalidNumber xmlns="http://www.dataaccess.com/webservicesserver/"><Number>bad</Number></InvalidNumber></soap:Body></soap:Envelope>`))
+		default:
+			w.Write([]byte(report))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		addressing  *Addressing
+		wantChunked bool
+		wantRows    int
+		wantFault   string
+		wantErr     error
+	}{
+		{name: "Streamed request and response", wantChunked: true, wantRows: 10000},
+		{name: "Request encoded in memory with WS-Addressing", addressing: &Addressing{}, wantRows: 10000},
+		{name: "Fault", path: "/fault", wantChunked: true, wantFault: "Server", wantErr: ErrFault},
+		{name: "Payload fault", path: "/invalid", wantChunked: true, wantErr: ErrStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Report{}
+			invalid := &InvalidNumber{}
+			resp, err := New().SetStreaming(true).R().
+				SetUrl(server.URL + tt.path).
+				SetAddressing(tt.addressing).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				SetPayloadResponse(result).
+				SetPayloadFault(invalid).
+				Call()
+			if tt.wantErr == nil && err != nil || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Call() error = %v, want %v", err, tt.wantErr)
+			}
+			if chunked != tt.wantChunked {
+				t.Errorf("chunked request = %v, want %v", chunked, tt.wantChunked)
+			}
+			if !strings.Contains(string(request), "<ubiNum>7</ubiNum>") {
+				t.Errorf("request = %s, want the envelope", request)
+			}
+			if len(result.Rows) != tt.wantRows {
+				t.Errorf("Rows = %v, want %v", len(result.Rows), tt.wantRows)
+			}
+			if tt.wantFault != "" && (resp.Fault() == nil || resp.Fault().CodeLocal() != tt.wantFault) {
+				t.Errorf("Fault() = %v, want %v", resp.Fault(), tt.wantFault)
+			}
+			if tt.path == "/invalid" && invalid.Number != "bad" {
+				t.Errorf("PayloadFault = %v, want the decoded fault", invalid)
+			}
+		})
+	}
+}
+
+func TestRequest_Call_MaxResponseSize(t *testing.T) {
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>seven</NumberToWordsResult></NumberToWordsResponse></soap:Body></soap:Envelope>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	size := int64(len(response))
+	tests := []struct {
+		name      string
+		client    *Client
+		streaming bool
+		max       int64
+		wantErr   bool
+	}{
+		{name: "Response at the maximum", client: New(), max: size},
+		{name: "Response over the maximum", client: New(), max: size - 1, wantErr: true},
+		{name: "Client maximum", client: New().SetMaxResponseSize(size - 1), wantErr: true},
+		{name: "Streamed response at the maximum", client: New(), streaming: true, max: size},
+		{name: "Streamed response over the maximum", client: New(), streaming: true, max: 100, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &NumberToWordsResponse{}
+			_, err := tt.client.R().
+				SetUrl(server.URL).
+				SetStreaming(tt.streaming).
+				SetMaxResponseSize(tt.max).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				SetPayloadResponse(result).
+				Call()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (!errors.Is(err, ErrRead) || !errors.Is(err, ErrResponseTooLarge)) {
+				t.Errorf("Call() error = %v, want ErrRead caused by ErrResponseTooLarge", err)
+			}
+			if !tt.wantErr && result.NumberToWordsResult != "seven" {
+				t.Errorf("NumberToWordsResult = %v, want seven", result.NumberToWordsResult)
+			}
+		})
+	}
+}
+
+func TestRequest_Call_Streaming_MarshalError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	_, err := New().R().
+		SetUrl(server.URL).
+		SetStreaming(true).
+		SetPayloadRequest(map[string]string{"a": "b"}).
+		Call()
+	if !errors.Is(err, ErrMarshal) {
+		t.Errorf("Call() error = %v, want ErrMarshal", err)
+	}
+}
+
+func TestRequest_Call_Streaming_Leak(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response is sent before the request body is read.
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "Refused connection", url: "http://127.0.0.1:1"},
+		{name: "Early response", url: server.URL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			for i := 0; i < 20; i++ {
+				_, err := New().R().
+					SetUrl(tt.url).
+					SetStreaming(true).
+					SetPayloadRequest(&NumberToWords{UbiNum: strings.Repeat("7", 1<<20)}).
+					Call()
+				if errors.Is(err, ErrMarshal) {
+					t.Fatalf("Call() error = %v, want no ErrMarshal", err)
+				}
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for runtime.NumGoroutine() > before+2 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if after := runtime.NumGoroutine(); after > before+2 {
+				t.Errorf("goroutines = %v after the calls, want about %v", after, before)
+			}
+		})
+	}
+}
+
+func Test_limitedReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		max     int64
+		want    string
+		wantErr error
+	}{
+		{name: "Under the limit", data: "abc", max: 4, want: "abc"},
+		{name: "At the limit", data: "abcd", max: 4, want: "abcd"},
+		{name: "Over the limit", data: "abcde", max: 4, want: "abcd", wantErr: ErrResponseTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ioutil.ReadAll(&limitedReader{r: iotest.OneByteReader(strings.NewReader(tt.data)), remaining: tt.max})
+			if err != tt.wantErr {
+				t.Errorf("ReadAll() error = %v, want %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadAll() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package soap
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// DefaultClockSkew is the clock skew tolerated on the response Timestamp
+// when the Verifier sets none.
+const DefaultClockSkew = 5 * time.Minute
+
+// Verifier struct configures the verification of the XML digital signature
+// of the response envelopes. The signature must cover the Body and the
+// `wsu:Timestamp` of the `wsse:Security` header, and be made with a trusted
+// certificate. A response failing the verification, SOAP Faults included,
+// is returned with an ErrSignature error.
+//
+//	client.SetWSSecurity(&soap.WSSecurity{
+//		Verifier: &soap.Verifier{
+//			Certificates: []*x509.Certificate{serviceCert},
+//			ClockSkew:    time.Minute,
+//		},
+//	})
+type Verifier struct {
+	// Certificates are trusted to sign the responses. They are also looked
+	// up by the signatures referring to a key identifier.
+	Certificates []*x509.Certificate
+	// Roots trusts the certificates sent in the response that chain up to
+	// one of its authorities.
+	Roots *x509.CertPool
+	// ClockSkew is the tolerance on the Created and Expires times of the
+	// Timestamp, defaults to DefaultClockSkew.
+	ClockSkew time.Duration
+}
+
+// verify checks the signature of the response envelope.
+func (v *Verifier) verify(data []byte) error {
+	envelope, err := parseXML(data)
+	if err != nil {
+		return err
+	}
+	header, body, err := envelopeParts(envelope)
+	if err != nil {
+		return err
+	}
+	var security *xmlElement
+	if header != nil {
+		security = header.child(WSSENamespace, "Security")
+	}
+	if security == nil {
+		return errors.New("soap: response has no wsse:Security header")
+	}
+	signature := security.child(DSigNamespace, "Signature")
+	if signature == nil {
+		return errors.New("soap: response is not signed")
+	}
+	signedInfo := signature.child(DSigNamespace, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("soap: signature has no SignedInfo")
+	}
+	timestamp := security.child(WSUNamespace, "Timestamp")
+	if timestamp == nil {
+		return errors.New("soap: response has no wsu:Timestamp")
+	}
+
+	signed, err := verifyReferences(envelope, signedInfo)
+	if err != nil {
+		return err
+	}
+	for _, part := range []*xmlElement{body, timestamp} {
+		if !signed[part] {
+			return fmt.Errorf("soap: signature does not cover the %s", part.local)
+		}
+	}
+	if err := v.checkTimestamp(timestamp); err != nil {
+		return err
+	}
+
+	cert, err := v.certificate(security, signature)
+	if err != nil {
+		return err
+	}
+	return verifySignatureValue(cert, signedInfo, signature)
+}
+
+// envelopeParts returns the Header and the Body of the envelope, rejecting
+// any other element, so that the signed Body is the one decoded.
+func envelopeParts(envelope *xmlElement) (header, body *xmlElement, err error) {
+	namespace := envelope.namespace()
+	for _, child := range envelope.elements() {
+		switch {
+		case body == nil && header == nil && child.is(namespace, "Header"):
+			header = child
+		case body == nil && child.is(namespace, "Body"):
+			body = child
+		default:
+			return nil, nil, fmt.Errorf("soap: unexpected %s element in the envelope", child.local)
+		}
+	}
+	if body == nil {
+		return nil, nil, errBodyNotFound
+	}
+	return header, body, nil
+}
+
+// verifyReferences checks the digest of the references of SignedInfo and
+// returns the signed elements.
+func verifyReferences(envelope, signedInfo *xmlElement) (map[*xmlElement]bool, error) {
+	signed := map[*xmlElement]bool{}
+	for _, ref := range signedInfo.elements() {
+		if !ref.is(DSigNamespace, "Reference") {
+			continue
+		}
+		uri, _ := ref.attr("", "URI")
+		target, err := referencedElement(envelope, uri)
+		if err != nil {
+			return nil, err
+		}
+
+		var inclusive []string
+		if transforms := ref.child(DSigNamespace, "Transforms"); transforms != nil {
+			for _, transform := range transforms.elements() {
+				if algorithm, _ := transform.attr("", "Algorithm"); algorithm != ExcC14N {
+					return nil, fmt.Errorf("soap: unsupported transform %q", algorithm)
+				}
+				inclusive = inclusiveNamespaces(transform)
+			}
+		}
+		method := ref.child(DSigNamespace, "DigestMethod")
+		if method == nil {
+			return nil, fmt.Errorf("soap: reference %s has no DigestMethod", uri)
+		}
+		algorithm, _ := method.attr("", "Algorithm")
+		hash, ok := digestMethods[algorithm]
+		if !ok {
+			return nil, fmt.Errorf("soap: unsupported digest algorithm %q", algorithm)
+		}
+		h := hash.New()
+		h.Write(canonicalize(target, inclusive))
+
+		digest := ref.child(DSigNamespace, "DigestValue")
+		if digest == nil {
+			return nil, fmt.Errorf("soap: reference %s has no DigestValue", uri)
+		}
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(digest.text()))
+		if err != nil || subtle.ConstantTimeCompare(value, h.Sum(nil)) != 1 {
+			return nil, fmt.Errorf("soap: digest of reference %s does not match", uri)
+		}
+		signed[target] = true
+	}
+	return signed, nil
+}
+
+// referencedElement returns the element of the same document reference
+// URI, "#" followed by its wsu:Id or Id attribute. Several elements with the
+// identifier are rejected as they could hide a wrapped payload.
+func referencedElement(envelope *xmlElement, uri string) (*xmlElement, error) {
+	if !strings.HasPrefix(uri, "#") || len(uri) == 1 {
+		return nil, fmt.Errorf("soap: unsupported reference URI %q", uri)
+	}
+	id := uri[1:]
+	var found []*xmlElement
+	envelope.find(func(e *xmlElement) bool {
+		for _, name := range [][2]string{{WSUNamespace, "Id"}, {"", "Id"}, {"", "ID"}, {xmlNamespace, "id"}} {
+			if value, ok := e.attr(name[0], name[1]); ok && value == id {
+				found = append(found, e)
+				break
+			}
+		}
+		return false
+	})
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("soap: referenced element %s not found", uri)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("soap: several elements are identified by %s", uri)
+	}
+}
+
+// inclusiveNamespaces returns the PrefixList of the InclusiveNamespaces
+// parameter of an exclusive canonicalization algorithm element.
+func inclusiveNamespaces(algorithm *xmlElement) []string {
+	if e := algorithm.child(ExcC14N, "InclusiveNamespaces"); e != nil {
+		list, _ := e.attr("", "PrefixList")
+		return strings.Fields(list)
+	}
+	return nil
+}
+
+// checkTimestamp checks that the Timestamp is created and not expired,
+// give or take the clock skew.
+func (v *Verifier) checkTimestamp(timestamp *xmlElement) error {
+	skew := v.ClockSkew
+	if skew <= 0 {
+		skew = DefaultClockSkew
+	}
+	current := now()
+
+	created := timestamp.child(WSUNamespace, "Created")
+	if created == nil {
+		return errors.New("soap: timestamp has no Created time")
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(created.text()))
+	if err != nil {
+		return fmt.Errorf("soap: timestamp Created: %w", err)
+	}
+	if t.After(current.Add(skew)) {
+		return fmt.Errorf("soap: timestamp created in the future at %s", t.Format(time.RFC3339))
+	}
+
+	if expires := timestamp.child(WSUNamespace, "Expires"); expires != nil {
+		t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(expires.text()))
+		if err != nil {
+			return fmt.Errorf("soap: timestamp Expires: %w", err)
+		}
+		if t.Before(current.Add(-skew)) {
+			return fmt.Errorf("soap: timestamp expired at %s", t.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// certificate returns the certificate of the signature KeyInfo, from a
+// referenced `wsse:BinarySecurityToken`, a subject key identifier or an
+// embedded X509Certificate, once checked against the trusted ones.
+func (v *Verifier) certificate(security, signature *xmlElement) (*x509.Certificate, error) {
+	keyInfo := signature.child(DSigNamespace, "KeyInfo")
+	if keyInfo == nil {
+		return nil, errors.New("soap: signature has no KeyInfo")
+	}
+
+	var cert *x509.Certificate
+	var raw string
+	if ref := keyInfo.child(WSSENamespace, "SecurityTokenReference"); ref != nil {
+		if reference := ref.child(WSSENamespace, "Reference"); reference != nil {
+			uri, _ := reference.attr("", "URI")
+			token, err := referencedElement(security, uri)
+			if err != nil {
+				return nil, err
+			}
+			if !token.is(WSSENamespace, "BinarySecurityToken") {
+				return nil, fmt.Errorf("soap: token %s is not a BinarySecurityToken", uri)
+			}
+			raw = token.text()
+		} else if identifier := ref.child(WSSENamespace, "KeyIdentifier"); identifier != nil {
+			if valueType, _ := identifier.attr("", "ValueType"); valueType != SubjectKeyIdentifierURI {
+				return nil, fmt.Errorf("soap: unsupported key identifier %q", valueType)
+			}
+			ski, err := base64.StdEncoding.DecodeString(strings.TrimSpace(identifier.text()))
+			if err != nil {
+				return nil, fmt.Errorf("soap: key identifier: %w", err)
+			}
+			for _, c := range v.Certificates {
+				if len(c.SubjectKeyId) > 0 && bytes.Equal(c.SubjectKeyId, ski) {
+					return c, nil
+				}
+			}
+			return nil, errors.New("soap: no trusted certificate has the key identifier")
+		}
+	} else if data := keyInfo.child(DSigNamespace, "X509Data"); data != nil {
+		if c := data.child(DSigNamespace, "X509Certificate"); c != nil {
+			raw = c.text()
+		}
+	}
+	if raw == "" {
+		return nil, errors.New("soap: unsupported signature KeyInfo")
+	}
+
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(raw), ""))
+	if err != nil {
+		return nil, fmt.Errorf("soap: signing certificate: %w", err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		return nil, fmt.Errorf("soap: signing certificate: %w", err)
+	}
+	if !v.trusts(cert) {
+		return nil, fmt.Errorf("soap: signing certificate %q is not trusted", cert.Subject.CommonName)
+	}
+	return cert, nil
+}
+
+// trusts reports whether the certificate is one of the trusted certificates
+// or chains up to the roots.
+func (v *Verifier) trusts(cert *x509.Certificate) bool {
+	for _, c := range v.Certificates {
+		if bytes.Equal(c.Raw, cert.Raw) {
+			return true
+		}
+	}
+	if v.Roots == nil {
+		return false
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:       v.Roots,
+		CurrentTime: now(),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil
+}
+
+// verifySignatureValue checks the SignatureValue of the canonical
+// SignedInfo with the public key of the certificate.
+func verifySignatureValue(cert *x509.Certificate, signedInfo, signature *xmlElement) error {
+	c14n := signedInfo.child(DSigNamespace, "CanonicalizationMethod")
+	if c14n == nil {
+		return errors.New("soap: signature has no CanonicalizationMethod")
+	}
+	if algorithm, _ := c14n.attr("", "Algorithm"); algorithm != ExcC14N {
+		return fmt.Errorf("soap: unsupported canonicalization %q", algorithm)
+	}
+	method := signedInfo.child(DSigNamespace, "SignatureMethod")
+	if method == nil {
+		return errors.New("soap: signature has no SignatureMethod")
+	}
+	algorithm, _ := method.attr("", "Algorithm")
+	m, ok := signatureMethods[algorithm]
+	if !ok {
+		return fmt.Errorf("soap: unsupported signature algorithm %q", algorithm)
+	}
+	valueElement := signature.child(DSigNamespace, "SignatureValue")
+	if valueElement == nil {
+		return errors.New("soap: signature has no SignatureValue")
+	}
+	value, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(valueElement.text()), ""))
+	if err != nil {
+		return fmt.Errorf("soap: signature value: %w", err)
+	}
+
+	h := m.hash.New()
+	h.Write(canonicalize(signedInfo, inclusiveNamespaces(c14n)))
+	digest := h.Sum(nil)
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if m.ecdsa {
+			return fmt.Errorf("soap: %s needs an ECDSA key", algorithm)
+		}
+		if rsa.VerifyPKCS1v15(key, m.hash, digest, value) == nil {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if !m.ecdsa || len(value) != 2*size {
+			return fmt.Errorf("soap: %s does not match the ECDSA key", algorithm)
+		}
+		r, s := new(big.Int).SetBytes(value[:size]), new(big.Int).SetBytes(value[size:])
+		if ecdsa.Verify(key, digest, r, s) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("soap: unsupported signing key %T", cert.PublicKey)
+	}
+	return errors.New("soap: signature value does not match")
+}
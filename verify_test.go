@@ -0,0 +1,220 @@
+package soap
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// signedResponse returns a NumberToWordsResponse envelope secured by security
+// at the signing time.
+func signedResponse(t *testing.T, security *WSSecurity, signing time.Time) []byte {
+	t.Helper()
+	now = func() time.Time { return signing }
+	defer func() { now = time.Now }()
+
+	data, err := marshalEnvelope(SOAP11, &NumberToWordsResponse{NumberToWordsResult: "seven"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = security.secure(data); err != nil {
+		t.Fatalf("secure() error = %v", err)
+	}
+	return data
+}
+
+func TestRequest_Call_Verifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaCert, ecCert, otherCert := newTestCertificate(t, rsaKey), newTestCertificate(t, ecKey), newTestCertificate(t, otherKey)
+	roots := x509.NewCertPool()
+	roots.AddCert(rsaCert)
+
+	current := time.Now()
+	rsaSigned := &WSSecurity{TimestampTTL: time.Minute, Signer: &Signer{Certificate: rsaCert, PrivateKey: rsaKey}}
+	valid := signedResponse(t, rsaSigned, current)
+	otherBody := `<x:Body xmlns:x="urn:other"><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>EVIL</NumberToWordsResult></NumberToWordsResponse></x:Body>`
+	secondBody := `<soap:Body><NumberToWordsResponse xmlns="http://www.dataaccess.com/webservicesserver/"><NumberToWordsResult>EVIL</NumberToWordsResult></NumberToWordsResponse></soap:Body>`
+
+	tests := []struct {
+		name       string
+		response   []byte
+		verifier   *Verifier
+		wantResult string
+		wantErr    bool
+	}{
+		{
+			name:       "Trusted binary security token",
+			response:   valid,
+			verifier:   &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantResult: "seven",
+		},
+		{
+			name:       "Binary security token chained to the roots",
+			response:   valid,
+			verifier:   &Verifier{Roots: roots},
+			wantResult: "seven",
+		},
+		{
+			name: "Key identifier of a trusted certificate",
+			response: signedResponse(t, &WSSecurity{
+				TimestampTTL: time.Minute,
+				Signer:       &Signer{Certificate: ecCert, PrivateKey: ecKey, KeyReference: KeyIdentifierReference},
+			}, current),
+			verifier:   &Verifier{Certificates: []*x509.Certificate{ecCert}},
+			wantResult: "seven",
+		},
+		{
+			name:       "Timestamp within the clock skew",
+			response:   signedResponse(t, rsaSigned, current.Add(-2*time.Minute)),
+			verifier:   &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantResult: "seven",
+		},
+		{
+			name:     "Expired timestamp",
+			response: signedResponse(t, rsaSigned, current.Add(-2*time.Minute)),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}, ClockSkew: 30 * time.Second},
+			wantErr:  true,
+		},
+		{
+			name:     "Timestamp created in the future",
+			response: signedResponse(t, rsaSigned, current.Add(time.Hour)),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Untrusted certificate",
+			response: valid,
+			verifier: &Verifier{Certificates: []*x509.Certificate{otherCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Tampered body",
+			response: bytes.Replace(valid, []byte("seven"), []byte("eight"), 1),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Timestamp not signed",
+			response: signedResponse(t, &WSSecurity{Signer: rsaSigned.Signer}, current),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Reference without DigestValue",
+			response: regexp.MustCompile(`<(\w+:)?DigestValue>[^<]*</(\w+:)?DigestValue>`).ReplaceAll(valid, nil),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Signature without SignatureValue",
+			response: regexp.MustCompile(`<(\w+:)?SignatureValue>[^<]*</(\w+:)?SignatureValue>`).ReplaceAll(valid, nil),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Unsigned Body in another namespace",
+			response: bytes.Replace(valid, []byte("<soap:Body"), []byte(otherBody+"<soap:Body"), 1),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Unsigned second Body",
+			response: bytes.Replace(valid, []byte("</soap:Body>"), []byte("</soap:Body>"+secondBody), 1),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Second Header",
+			response: bytes.Replace(valid, []byte("<soap:Body"), []byte("<soap:Header/><soap:Body"), 1),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+		{
+			name:     "Unsigned response",
+			response: signedResponse(t, &WSSecurity{TimestampTTL: time.Minute}, current),
+			verifier: &Verifier{Certificates: []*x509.Certificate{rsaCert}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(tt.response)
+			}))
+			defer server.Close()
+
+			result := &NumberToWordsResponse{}
+			resp, err := New().R().
+				SetUrl(server.URL).
+				SetWSSecurity(&WSSecurity{Verifier: tt.verifier}).
+				SetPayloadRequest(&NumberToWords{UbiNum: "7"}).
+				SetPayloadResponse(result).
+				Call()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrSignature) {
+					t.Errorf("Call() error = %v, want ErrSignature", err)
+				}
+				if resp == nil {
+					t.Errorf("Call() response = nil, want the rejected response")
+				}
+				return
+			}
+			if result.NumberToWordsResult != tt.wantResult {
+				t.Errorf("NumberToWordsResult = %v, want %v", result.NumberToWordsResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_referencedElement(t *testing.T) {
+	root, err := parseXML([]byte(`<e xmlns:wsu="` + WSUNamespace + `"><a wsu:Id="x"/><b Id="y"/><c Id="z"/><d wsu:Id="z"/></e>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		uri       string
+		wantLocal string
+		wantErr   bool
+	}{
+		{name: "wsu:Id", uri: "#x", wantLocal: "a"},
+		{name: "Id", uri: "#y", wantLocal: "b"},
+		{name: "Duplicated identifier", uri: "#z", wantErr: true},
+		{name: "Unknown identifier", uri: "#w", wantErr: true},
+		{name: "External URI", uri: "http://example.com/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := referencedElement(root, tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("referencedElement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.local != tt.wantLocal {
+				t.Errorf("referencedElement() = %v, want %v", got.local, tt.wantLocal)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package soap
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	// Envelope12Namespace is the SOAP 1.2 envelope namespace.
+	Envelope12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// SOAPVersion type is the SOAP protocol version used to build the envelope,
+// the HTTP headers and to parse faults.
+type SOAPVersion int
+
+const (
+	// SOAP11 is the SOAP 1.1 protocol, sent as `text/xml` with a `SOAPAction` header.
+	SOAP11 SOAPVersion = iota + 1
+	// SOAP12 is the SOAP 1.2 protocol, sent as `application/soap+xml` with an `action` parameter.
+	SOAP12
+)
+
+// String method returns the SOAP version number.
+func (v SOAPVersion) String() string {
+	switch v {
+	case SOAP11:
+		return "1.1"
+	case SOAP12:
+		return "1.2"
+	}
+	return fmt.Sprintf("SOAPVersion(%d)", int(v))
+}
+
+// Namespace method returns the envelope namespace of the SOAP version.
+func (v SOAPVersion) Namespace() string {
+	if v == SOAP12 {
+		return Envelope12Namespace
+	}
+	return EnvelopeNamespace
+}
+
+// ContentType method returns the HTTP `Content-Type` of the SOAP version.
+// SOAP 1.2 carries the action as a media type parameter.
+func (v SOAPVersion) ContentType(action string) string {
+	if v == SOAP12 {
+		if action == "" {
+			return "application/soap+xml; charset=utf-8"
+		}
+		return fmt.Sprintf("application/soap+xml; charset=utf-8; action=%q", action)
+	}
+	return "text/xml; charset=utf-8"
+}
+
+// setHeaders sets the protocol headers that were not set by the caller.
+func (v SOAPVersion) setHeaders(header http.Header, action string) {
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", v.ContentType(action))
+	}
+	if v != SOAP12 && action != "" && header.Get("SOAPAction") == "" {
+		header.Set("SOAPAction", fmt.Sprintf("%q", action))
+	}
+}
+
+// versionOf returns the SOAP version declared by an envelope namespace.
+func versionOf(namespace string) (SOAPVersion, bool) {
+	switch namespace {
+	case EnvelopeNamespace:
+		return SOAP11, true
+	case Envelope12Namespace:
+		return SOAP12, true
+	}
+	return 0, false
+}
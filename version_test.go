@@ -0,0 +1,125 @@
+package soap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSOAPVersion_ContentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		version SOAPVersion
+		action  string
+		want    string
+	}{
+		{name: "SOAP 1.1", version: SOAP11, action: "urn:add", want: "text/xml; charset=utf-8"},
+		{name: "SOAP 1.2 with action", version: SOAP12, action: "urn:add", want: `application/soap+xml; charset=utf-8; action="urn:add"`},
+		{name: "SOAP 1.2 without action", version: SOAP12, want: "application/soap+xml; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.version.ContentType(tt.action); got != tt.want {
+				t.Errorf("ContentType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSOAPVersion_Namespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		version SOAPVersion
+		want    string
+	}{
+		{name: "SOAP 1.1", version: SOAP11, want: EnvelopeNamespace},
+		{name: "SOAP 1.2", version: SOAP12, want: Envelope12Namespace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.version.Namespace(); got != tt.want {
+				t.Errorf("Namespace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSOAPVersion_setHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		version SOAPVersion
+		header  http.Header
+		action  string
+		want    http.Header
+	}{
+		{
+			name:    "SOAP 1.1 headers",
+			version: SOAP11,
+			header:  http.Header{},
+			action:  "urn:add",
+			want: http.Header{
+				"Content-Type": {"text/xml; charset=utf-8"},
+				"Soapaction":   {`"urn:add"`},
+			},
+		},
+		{
+			name:    "SOAP 1.2 headers",
+			version: SOAP12,
+			header:  http.Header{},
+			action:  "urn:add",
+			want: http.Header{
+				"Content-Type": {`application/soap+xml; charset=utf-8; action="urn:add"`},
+			},
+		},
+		{
+			name:    "Keep headers set by the caller",
+			version: SOAP11,
+			header:  http.Header{"Content-Type": {"text/xml"}, "Soapaction": {"urn:custom"}},
+			action:  "urn:add",
+			want:    http.Header{"Content-Type": {"text/xml"}, "Soapaction": {"urn:custom"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.version.setHeaders(tt.header, tt.action)
+			if !reflect.DeepEqual(tt.header, tt.want) {
+				t.Errorf("setHeaders() = %v, want %v", tt.header, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_Call_SOAP12(t *testing.T) {
+	var contentType, envelope string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := ioutil.ReadAll(r.Body)
+		envelope = string(body)
+		w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+			`<env:Code><env:Value>env:Receiver</env:Value></env:Code>` +
+			`<env:Reason><env:Text xml:lang="en">Service unavailable</env:Text></env:Reason>` +
+			`</env:Fault></env:Body></env:Envelope>`))
+	}))
+	defer server.Close()
+
+	_, err := New().SetSOAPVersion(SOAP12).R().
+		SetUrl(server.URL).
+		SetSOAPAction("urn:NumberToWords").
+		SetPayloadRequest(&NumberToWords{UbiNum: "9"}).
+		SetPayloadResponse(&NumberToWordsResponse{}).
+		Call()
+	if err == nil || err.Error() != "soap: fault env:Receiver: Service unavailable" {
+		t.Errorf("Call() error = %v, want SOAP 1.2 fault", err)
+	}
+	if want := `application/soap+xml; charset=utf-8; action="urn:NumberToWords"`; contentType != want {
+		t.Errorf("Call() Content-Type = %v, want %v", contentType, want)
+	}
+	if want := `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">`; !strings.HasPrefix(envelope, want) {
+		t.Errorf("Call() envelope = %v, want prefix %v", envelope, want)
+	}
+}
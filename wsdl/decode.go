@@ -0,0 +1,148 @@
+package wsdl
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// QName struct is a namespace qualified name, resolved from the `prefix:local`
+// value of WSDL and XSD attributes such as `type`, `element` or `binding`.
+type QName struct {
+	Space string
+	Local string
+}
+
+// String method returns the name in `{namespace}local` notation.
+func (q QName) String() string {
+	if q.Space == "" {
+		return q.Local
+	}
+	return "{" + q.Space + "}" + q.Local
+}
+
+// IsZero method reports whether the name is empty.
+func (q QName) IsZero() bool {
+	return q.Local == ""
+}
+
+// UnmarshalXMLAttr method parses the `{namespace}local` value produced by the decoder.
+func (q *QName) UnmarshalXMLAttr(attr xml.Attr) error {
+	*q = parseQName(attr.Value)
+	return nil
+}
+
+// QNames type is a space separated list of QNames, as in `memberTypes`.
+type QNames []QName
+
+// UnmarshalXMLAttr method parses the list of `{namespace}local` values produced by the decoder.
+func (q *QNames) UnmarshalXMLAttr(attr xml.Attr) error {
+	*q = nil
+	for _, name := range strings.Fields(attr.Value) {
+		*q = append(*q, parseQName(name))
+	}
+	return nil
+}
+
+func parseQName(v string) QName {
+	if strings.HasPrefix(v, "{") {
+		if i := strings.Index(v, "}"); i > 0 {
+			return QName{Space: v[1:i], Local: v[i+1:]}
+		}
+	}
+	return QName{Local: v}
+}
+
+// qnameAttrs are the attributes whose value is a QName.
+var qnameAttrs = map[string]bool{
+	"base":              true,
+	"binding":           true,
+	"element":           true,
+	"itemType":          true,
+	"memberTypes":       true,
+	"message":           true,
+	"ref":               true,
+	"substitutionGroup": true,
+	"type":              true,
+}
+
+// qnameReader is an xml.TokenReader that rewrites QName attribute values to
+// the `{namespace}local` notation using the namespaces in scope, which
+// encoding/xml does not expose while unmarshalling.
+type qnameReader struct {
+	d     *xml.Decoder
+	scope []map[string]string
+}
+
+func newDecoder(r io.Reader) *xml.Decoder {
+	return xml.NewTokenDecoder(&qnameReader{
+		d:     xml.NewDecoder(r),
+		scope: []map[string]string{{"xml": "http://www.w3.org/XML/1998/namespace"}},
+	})
+}
+
+// Token method returns the next token with its QName attributes resolved.
+func (r *qnameReader) Token() (xml.Token, error) {
+	tok, err := r.d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case xml.StartElement:
+		r.push(t)
+		t = t.Copy()
+		for i, attr := range t.Attr {
+			if attr.Name.Space == "" && qnameAttrs[attr.Name.Local] {
+				t.Attr[i].Value = r.resolve(attr.Value)
+			}
+		}
+		return t, nil
+	case xml.EndElement:
+		r.scope = r.scope[:len(r.scope)-1]
+	}
+	return tok, nil
+}
+
+func (r *qnameReader) push(start xml.StartElement) {
+	parent := r.scope[len(r.scope)-1]
+	var scope map[string]string
+	for _, attr := range start.Attr {
+		prefix := ""
+		switch {
+		case attr.Name.Space == "xmlns":
+			prefix = attr.Name.Local
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+		default:
+			continue
+		}
+		if scope == nil {
+			scope = make(map[string]string, len(parent)+1)
+			for k, v := range parent {
+				scope[k] = v
+			}
+		}
+		scope[prefix] = attr.Value
+	}
+	if scope == nil {
+		scope = parent
+	}
+	r.scope = append(r.scope, scope)
+}
+
+// resolve expands the space separated QNames of an attribute value.
+func (r *qnameReader) resolve(value string) string {
+	scope := r.scope[len(r.scope)-1]
+	names := strings.Fields(value)
+	for i, name := range names {
+		prefix, local := "", name
+		if j := strings.Index(name, ":"); j >= 0 {
+			prefix, local = name[:j], name[j+1:]
+		}
+		if space, ok := scope[prefix]; ok {
+			names[i] = "{" + space + "}" + local
+		} else if prefix == "" {
+			names[i] = local
+		}
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,63 @@
+package wsdl
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_newDecoder(t *testing.T) {
+	doc := `<root xmlns="urn:default" xmlns:a="urn:a">
+		<item type="a:One" ref="Two"/>
+		<item xmlns:a="urn:other" type="a:One" memberTypes="a:One b:Two"/>
+		<item type="a:One"/>
+	</root>`
+
+	var v struct {
+		Items []struct {
+			Type        QName  `xml:"type,attr"`
+			Ref         QName  `xml:"ref,attr"`
+			MemberTypes QNames `xml:"memberTypes,attr"`
+		} `xml:"item"`
+	}
+	if err := newDecoder(strings.NewReader(doc)).Decode(&v); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  QName
+		want QName
+	}{
+		{name: "Prefixed name", got: v.Items[0].Type, want: QName{Space: "urn:a", Local: "One"}},
+		{name: "Default namespace name", got: v.Items[0].Ref, want: QName{Space: "urn:default", Local: "Two"}},
+		{name: "Redeclared prefix", got: v.Items[1].Type, want: QName{Space: "urn:other", Local: "One"}},
+		{name: "Prefix scope restored", got: v.Items[2].Type, want: QName{Space: "urn:a", Local: "One"}},
+		{name: "List member", got: v.Items[1].MemberTypes[0], want: QName{Space: "urn:other", Local: "One"}},
+		{name: "Unknown prefix", got: v.Items[1].MemberTypes[1], want: QName{Local: "b:Two"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("QName = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQName_String(t *testing.T) {
+	tests := []struct {
+		name string
+		q    QName
+		want string
+	}{
+		{name: "Qualified", q: QName{Space: "urn:a", Local: "One"}, want: "{urn:a}One"},
+		{name: "Unqualified", q: QName{Local: "One"}, want: "One"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
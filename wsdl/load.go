@@ -0,0 +1,169 @@
+package wsdl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+)
+
+// ParseFile method reads the WSDL file and resolves its `wsdl:import`,
+// `xsd:import` and `xsd:include` of local files, relative to the importing
+// document. The imported definitions and schemas are merged in the result.
+func ParseFile(name string) (*Definitions, error) {
+	l := &loader{loaded: map[string]bool{}}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return l.definitions(path)
+}
+
+// loader keeps track of the files already loaded so that circular imports
+// are read only once.
+type loader struct {
+	loaded map[string]bool
+}
+
+func (l *loader) definitions(path string) (*Definitions, error) {
+	l.loaded[path] = true
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("wsdl: %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, s := range append([]*Schema(nil), d.Types.Schemas...) {
+		if err := l.schemaRefs(d, s, dir); err != nil {
+			return nil, err
+		}
+	}
+	for _, imp := range d.Imports {
+		if imp.Location == "" {
+			continue
+		}
+		location, err := resolve(dir, imp.Location)
+		if err != nil {
+			return nil, err
+		}
+		if l.loaded[location] {
+			continue
+		}
+		isSchema, err := isSchemaFile(location)
+		if err != nil {
+			return nil, err
+		}
+		if isSchema {
+			if err := l.schema(d, location, imp.Namespace); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		imported, err := l.definitions(location)
+		if err != nil {
+			return nil, err
+		}
+		d.merge(imported)
+	}
+	return d, nil
+}
+
+// schema loads an XSD file in the definitions types. A schema without target
+// namespace takes the namespace of the including schema.
+func (l *loader) schema(d *Definitions, path, namespace string) error {
+	if l.loaded[path] {
+		return nil
+	}
+	l.loaded[path] = true
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	s := &Schema{}
+	if err := newDecoder(bytes.NewReader(data)).Decode(s); err != nil {
+		return fmt.Errorf("wsdl: %s: %w", path, err)
+	}
+	if s.TargetNamespace == "" {
+		s.TargetNamespace = namespace
+	}
+	s.link()
+	d.Types.Schemas = append(d.Types.Schemas, s)
+	return l.schemaRefs(d, s, filepath.Dir(path))
+}
+
+func (l *loader) schemaRefs(d *Definitions, s *Schema, dir string) error {
+	for _, imp := range s.Imports {
+		if imp.SchemaLocation == "" {
+			continue
+		}
+		location, err := resolve(dir, imp.SchemaLocation)
+		if err != nil {
+			return err
+		}
+		if err := l.schema(d, location, imp.Namespace); err != nil {
+			return err
+		}
+	}
+	for _, inc := range s.Includes {
+		location, err := resolve(dir, inc.SchemaLocation)
+		if err != nil {
+			return err
+		}
+		if err := l.schema(d, location, s.TargetNamespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge appends the declarations of imported definitions.
+func (d *Definitions) merge(imported *Definitions) {
+	d.Types.Schemas = append(d.Types.Schemas, imported.Types.Schemas...)
+	d.Messages = append(d.Messages, imported.Messages...)
+	d.PortTypes = append(d.PortTypes, imported.PortTypes...)
+	d.Bindings = append(d.Bindings, imported.Bindings...)
+	d.Services = append(d.Services, imported.Services...)
+}
+
+// resolve returns the path of a location relative to the importing document.
+func resolve(dir, location string) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "":
+	case "file":
+		location = u.Path
+	default:
+		return "", fmt.Errorf("wsdl: unsupported import location %q, only local files are loaded", location)
+	}
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(dir, filepath.FromSlash(location))
+	}
+	return filepath.Clean(location), nil
+}
+
+// isSchemaFile reports whether the root element of the file is an XSD schema.
+func isSchemaFile(path string) (bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return false, fmt.Errorf("wsdl: %s: %w", path, err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Space == XSDNamespace && start.Name.Local == "schema", nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package wsdl
+
+import (
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	d, err := ParseFile("testdata/import/service.wsdl")
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	port := d.Service("OrdersService").Ports[0]
+	binding := d.Binding(port.Binding)
+	if binding == nil {
+		t.Fatalf("Binding(%v) = nil", port.Binding)
+	}
+	portType := d.PortType(binding.Type)
+	if portType == nil {
+		t.Fatalf("PortType(%v) = nil, want the imported port type", binding.Type)
+	}
+	input := d.Message(portType.Operation("GetOrder").Input.Message)
+	if input == nil {
+		t.Fatalf("Message() = nil, want the imported message")
+	}
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{name: "Imported XSD element", ok: d.Element(input.Parts[0].Element) != nil},
+		{name: "Imported XSD complex type", ok: d.ComplexType(QName{Space: "urn:example:orders", Local: "Order"}) != nil},
+		{name: "Included chameleon XSD type", ok: d.ComplexType(QName{Space: "urn:example:orders", Local: "Entity"}) != nil},
+		{name: "Included union type", ok: len(d.SimpleType(QName{Space: "urn:example:orders", Local: "Status"}).Union.MemberTypes) == 2},
+		{name: "Circular import loaded once", ok: len(d.Bindings) == 1 && len(d.Services) == 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.ok {
+				t.Errorf("%s not resolved", tt.name)
+			}
+		})
+	}
+}
+
+func TestParseFile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "Missing file", file: "testdata/missing.wsdl"},
+		{name: "Not a WSDL", file: "testdata/import/orders.xsd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseFile(tt.file); err == nil {
+				t.Errorf("ParseFile() error = nil, want error")
+			}
+		})
+	}
+}
+
+func Test_resolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Relative path", location: "types/orders.xsd", want: "/wsdl/types/orders.xsd"},
+		{name: "Parent path", location: "../orders.xsd", want: "/orders.xsd"},
+		{name: "File URL", location: "file:///schemas/orders.xsd", want: "/schemas/orders.xsd"},
+		{name: "Remote URL", location: "http://example.com/orders.xsd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolve("/wsdl", tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
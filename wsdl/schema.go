@@ -0,0 +1,370 @@
+package wsdl
+
+import "encoding/xml"
+
+// XSDNamespace is the XML Schema namespace.
+const XSDNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// Schema struct is an XML Schema declared in the WSDL types or loaded from
+// an imported or included XSD file.
+type Schema struct {
+	TargetNamespace      string            `xml:"targetNamespace,attr"`
+	ElementFormDefault   string            `xml:"elementFormDefault,attr"`
+	AttributeFormDefault string            `xml:"attributeFormDefault,attr"`
+	Imports              []*SchemaImport   `xml:"http://www.w3.org/2001/XMLSchema import"`
+	Includes             []*SchemaInclude  `xml:"http://www.w3.org/2001/XMLSchema include"`
+	Elements             []*Element        `xml:"http://www.w3.org/2001/XMLSchema element"`
+	Attributes           []*Attribute      `xml:"http://www.w3.org/2001/XMLSchema attribute"`
+	ComplexTypes         []*ComplexType    `xml:"http://www.w3.org/2001/XMLSchema complexType"`
+	SimpleTypes          []*SimpleType     `xml:"http://www.w3.org/2001/XMLSchema simpleType"`
+	Groups               []*Group          `xml:"http://www.w3.org/2001/XMLSchema group"`
+	AttributeGroups      []*AttributeGroup `xml:"http://www.w3.org/2001/XMLSchema attributeGroup"`
+}
+
+// SchemaImport struct is an `xsd:import` of another namespace.
+type SchemaImport struct {
+	Namespace      string `xml:"namespace,attr"`
+	SchemaLocation string `xml:"schemaLocation,attr"`
+}
+
+// SchemaInclude struct is an `xsd:include` of a schema of the same namespace.
+type SchemaInclude struct {
+	SchemaLocation string `xml:"schemaLocation,attr"`
+}
+
+// Qualified method reports whether local elements of the schema are namespace qualified.
+func (s *Schema) Qualified() bool {
+	return s.ElementFormDefault == "qualified"
+}
+
+// Element struct is an `xsd:element` declaration or reference.
+type Element struct {
+	Name        string       `xml:"name,attr"`
+	Type        QName        `xml:"type,attr"`
+	Ref         QName        `xml:"ref,attr"`
+	MinOccurs   string       `xml:"minOccurs,attr"`
+	MaxOccurs   string       `xml:"maxOccurs,attr"`
+	Nillable    bool         `xml:"nillable,attr"`
+	Form        string       `xml:"form,attr"`
+	Default     string       `xml:"default,attr"`
+	Fixed       string       `xml:"fixed,attr"`
+	ComplexType *ComplexType `xml:"http://www.w3.org/2001/XMLSchema complexType"`
+	SimpleType  *SimpleType  `xml:"http://www.w3.org/2001/XMLSchema simpleType"`
+	Doc         string       `xml:"http://www.w3.org/2001/XMLSchema annotation>documentation"`
+
+	// Schema is the schema declaring a top level element, nil for local elements.
+	Schema *Schema `xml:"-"`
+}
+
+// Optional method reports whether the element may be omitted.
+func (e *Element) Optional() bool {
+	return e.MinOccurs == "0"
+}
+
+// Repeated method reports whether the element may occur more than once.
+func (e *Element) Repeated() bool {
+	return e.MaxOccurs != "" && e.MaxOccurs != "0" && e.MaxOccurs != "1"
+}
+
+// Attribute struct is an `xsd:attribute` declaration or reference.
+type Attribute struct {
+	Name       string      `xml:"name,attr"`
+	Type       QName       `xml:"type,attr"`
+	Ref        QName       `xml:"ref,attr"`
+	Use        string      `xml:"use,attr"`
+	Form       string      `xml:"form,attr"`
+	Default    string      `xml:"default,attr"`
+	Fixed      string      `xml:"fixed,attr"`
+	SimpleType *SimpleType `xml:"http://www.w3.org/2001/XMLSchema simpleType"`
+}
+
+// ComplexType struct is an `xsd:complexType` definition.
+type ComplexType struct {
+	Name            string               `xml:"name,attr"`
+	Abstract        bool                 `xml:"abstract,attr"`
+	Mixed           bool                 `xml:"mixed,attr"`
+	Sequence        *ModelGroup          `xml:"http://www.w3.org/2001/XMLSchema sequence"`
+	Choice          *ModelGroup          `xml:"http://www.w3.org/2001/XMLSchema choice"`
+	All             *ModelGroup          `xml:"http://www.w3.org/2001/XMLSchema all"`
+	Group           *GroupRef            `xml:"http://www.w3.org/2001/XMLSchema group"`
+	Attributes      []*Attribute         `xml:"http://www.w3.org/2001/XMLSchema attribute"`
+	AttributeGroups []*AttributeGroupRef `xml:"http://www.w3.org/2001/XMLSchema attributeGroup"`
+	ComplexContent  *Content             `xml:"http://www.w3.org/2001/XMLSchema complexContent"`
+	SimpleContent   *Content             `xml:"http://www.w3.org/2001/XMLSchema simpleContent"`
+	Doc             string               `xml:"http://www.w3.org/2001/XMLSchema annotation>documentation"`
+
+	// Schema is the schema declaring a named type, nil for anonymous types.
+	Schema *Schema `xml:"-"`
+}
+
+// ModelGroup struct is an `xsd:sequence`, `xsd:choice` or `xsd:all` particle.
+type ModelGroup struct {
+	MinOccurs string        `xml:"minOccurs,attr"`
+	MaxOccurs string        `xml:"maxOccurs,attr"`
+	Elements  []*Element    `xml:"http://www.w3.org/2001/XMLSchema element"`
+	Sequences []*ModelGroup `xml:"http://www.w3.org/2001/XMLSchema sequence"`
+	Choices   []*ModelGroup `xml:"http://www.w3.org/2001/XMLSchema choice"`
+	Groups    []*GroupRef   `xml:"http://www.w3.org/2001/XMLSchema group"`
+	Any       []*Any        `xml:"http://www.w3.org/2001/XMLSchema any"`
+
+	// Particles holds the children of the group in document order, which
+	// is the order of the elements of a sequence.
+	Particles []*Particle `xml:"-"`
+}
+
+// Particle struct is a child of a model group, only one field is set.
+type Particle struct {
+	Element  *Element
+	Sequence *ModelGroup
+	Choice   *ModelGroup
+	Group    *GroupRef
+	Any      *Any
+}
+
+// UnmarshalXML method decodes the model group, keeping the order of its
+// particles.
+func (g *ModelGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name {
+		case xml.Name{Local: "minOccurs"}:
+			g.MinOccurs = a.Value
+		case xml.Name{Local: "maxOccurs"}:
+			g.MaxOccurs = a.Value
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			p := &Particle{}
+			var v interface{}
+			switch t.Name {
+			case xml.Name{Space: XSDNamespace, Local: "element"}:
+				p.Element = &Element{}
+				g.Elements = append(g.Elements, p.Element)
+				v = p.Element
+			case xml.Name{Space: XSDNamespace, Local: "sequence"}:
+				p.Sequence = &ModelGroup{}
+				g.Sequences = append(g.Sequences, p.Sequence)
+				v = p.Sequence
+			case xml.Name{Space: XSDNamespace, Local: "choice"}:
+				p.Choice = &ModelGroup{}
+				g.Choices = append(g.Choices, p.Choice)
+				v = p.Choice
+			case xml.Name{Space: XSDNamespace, Local: "group"}:
+				p.Group = &GroupRef{}
+				g.Groups = append(g.Groups, p.Group)
+				v = p.Group
+			case xml.Name{Space: XSDNamespace, Local: "any"}:
+				p.Any = &Any{}
+				g.Any = append(g.Any, p.Any)
+				v = p.Any
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.DecodeElement(v, &t); err != nil {
+				return err
+			}
+			g.Particles = append(g.Particles, p)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// Any struct is an `xsd:any` wildcard.
+type Any struct {
+	Namespace string `xml:"namespace,attr"`
+	MinOccurs string `xml:"minOccurs,attr"`
+	MaxOccurs string `xml:"maxOccurs,attr"`
+}
+
+// Content struct is the `xsd:complexContent` or `xsd:simpleContent` of a complex type.
+type Content struct {
+	Extension   *Derivation `xml:"http://www.w3.org/2001/XMLSchema extension"`
+	Restriction *Derivation `xml:"http://www.w3.org/2001/XMLSchema restriction"`
+}
+
+// Derivation struct is the `xsd:extension` or `xsd:restriction` of a base type.
+type Derivation struct {
+	Base            QName                `xml:"base,attr"`
+	Sequence        *ModelGroup          `xml:"http://www.w3.org/2001/XMLSchema sequence"`
+	Choice          *ModelGroup          `xml:"http://www.w3.org/2001/XMLSchema choice"`
+	All             *ModelGroup          `xml:"http://www.w3.org/2001/XMLSchema all"`
+	Group           *GroupRef            `xml:"http://www.w3.org/2001/XMLSchema group"`
+	Attributes      []*Attribute         `xml:"http://www.w3.org/2001/XMLSchema attribute"`
+	AttributeGroups []*AttributeGroupRef `xml:"http://www.w3.org/2001/XMLSchema attributeGroup"`
+}
+
+// SimpleType struct is an `xsd:simpleType` definition.
+type SimpleType struct {
+	Name        string       `xml:"name,attr"`
+	Restriction *Restriction `xml:"http://www.w3.org/2001/XMLSchema restriction"`
+	List        *List        `xml:"http://www.w3.org/2001/XMLSchema list"`
+	Union       *Union       `xml:"http://www.w3.org/2001/XMLSchema union"`
+	Doc         string       `xml:"http://www.w3.org/2001/XMLSchema annotation>documentation"`
+
+	// Schema is the schema declaring a named type, nil for anonymous types.
+	Schema *Schema `xml:"-"`
+}
+
+// Restriction struct is the `xsd:restriction` facets of a simple type.
+type Restriction struct {
+	Base         QName    `xml:"base,attr"`
+	Enumerations []*Facet `xml:"http://www.w3.org/2001/XMLSchema enumeration"`
+	Pattern      *Facet   `xml:"http://www.w3.org/2001/XMLSchema pattern"`
+	Length       *Facet   `xml:"http://www.w3.org/2001/XMLSchema length"`
+	MinLength    *Facet   `xml:"http://www.w3.org/2001/XMLSchema minLength"`
+	MaxLength    *Facet   `xml:"http://www.w3.org/2001/XMLSchema maxLength"`
+	MinInclusive *Facet   `xml:"http://www.w3.org/2001/XMLSchema minInclusive"`
+	MaxInclusive *Facet   `xml:"http://www.w3.org/2001/XMLSchema maxInclusive"`
+}
+
+// Facet struct is a constraining facet value.
+type Facet struct {
+	Value string `xml:"value,attr"`
+	Doc   string `xml:"http://www.w3.org/2001/XMLSchema annotation>documentation"`
+}
+
+// List struct is an `xsd:list` of simple values.
+type List struct {
+	ItemType   QName       `xml:"itemType,attr"`
+	SimpleType *SimpleType `xml:"http://www.w3.org/2001/XMLSchema simpleType"`
+}
+
+// Union struct is an `xsd:union` of simple types.
+type Union struct {
+	MemberTypes QNames        `xml:"memberTypes,attr"`
+	SimpleTypes []*SimpleType `xml:"http://www.w3.org/2001/XMLSchema simpleType"`
+}
+
+// Group struct is a named `xsd:group` model group.
+type Group struct {
+	Name     string      `xml:"name,attr"`
+	Sequence *ModelGroup `xml:"http://www.w3.org/2001/XMLSchema sequence"`
+	Choice   *ModelGroup `xml:"http://www.w3.org/2001/XMLSchema choice"`
+	All      *ModelGroup `xml:"http://www.w3.org/2001/XMLSchema all"`
+
+	Schema *Schema `xml:"-"`
+}
+
+// GroupRef struct is a reference to a named model group.
+type GroupRef struct {
+	Ref       QName  `xml:"ref,attr"`
+	MinOccurs string `xml:"minOccurs,attr"`
+	MaxOccurs string `xml:"maxOccurs,attr"`
+}
+
+// AttributeGroup struct is a named `xsd:attributeGroup`.
+type AttributeGroup struct {
+	Name            string               `xml:"name,attr"`
+	Attributes      []*Attribute         `xml:"http://www.w3.org/2001/XMLSchema attribute"`
+	AttributeGroups []*AttributeGroupRef `xml:"http://www.w3.org/2001/XMLSchema attributeGroup"`
+
+	Schema *Schema `xml:"-"`
+}
+
+// AttributeGroupRef struct is a reference to a named attribute group.
+type AttributeGroupRef struct {
+	Ref QName `xml:"ref,attr"`
+}
+
+// link sets the Schema of the top level declarations.
+func (s *Schema) link() {
+	for _, e := range s.Elements {
+		e.Schema = s
+	}
+	for _, t := range s.ComplexTypes {
+		t.Schema = s
+	}
+	for _, t := range s.SimpleTypes {
+		t.Schema = s
+	}
+	for _, g := range s.Groups {
+		g.Schema = s
+	}
+	for _, g := range s.AttributeGroups {
+		g.Schema = s
+	}
+}
+
+// Element method returns the top level element declared with the name, nil if none.
+func (d *Definitions) Element(name QName) *Element {
+	for _, s := range d.Types.Schemas {
+		if s.TargetNamespace != name.Space {
+			continue
+		}
+		for _, e := range s.Elements {
+			if e.Name == name.Local {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// ComplexType method returns the complex type declared with the name, nil if none.
+func (d *Definitions) ComplexType(name QName) *ComplexType {
+	for _, s := range d.Types.Schemas {
+		if s.TargetNamespace != name.Space {
+			continue
+		}
+		for _, t := range s.ComplexTypes {
+			if t.Name == name.Local {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// SimpleType method returns the simple type declared with the name, nil if none.
+func (d *Definitions) SimpleType(name QName) *SimpleType {
+	for _, s := range d.Types.Schemas {
+		if s.TargetNamespace != name.Space {
+			continue
+		}
+		for _, t := range s.SimpleTypes {
+			if t.Name == name.Local {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// Group method returns the model group declared with the name, nil if none.
+func (d *Definitions) Group(name QName) *Group {
+	for _, s := range d.Types.Schemas {
+		if s.TargetNamespace != name.Space {
+			continue
+		}
+		for _, g := range s.Groups {
+			if g.Name == name.Local {
+				return g
+			}
+		}
+	}
+	return nil
+}
+
+// AttributeGroup method returns the attribute group declared with the name, nil if none.
+func (d *Definitions) AttributeGroup(name QName) *AttributeGroup {
+	for _, s := range d.Types.Schemas {
+		if s.TargetNamespace != name.Space {
+			continue
+		}
+		for _, g := range s.AttributeGroups {
+			if g.Name == name.Local {
+				return g
+			}
+		}
+	}
+	return nil
+}
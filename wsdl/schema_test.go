@@ -0,0 +1,121 @@
+package wsdl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefinitions_Element(t *testing.T) {
+	d := parseTestFile(t, "testdata/numberconversion.wsdl")
+
+	tests := []struct {
+		name     string
+		element  QName
+		wantNil  bool
+		wantType QName
+	}{
+		{name: "Anonymous type element", element: QName{Space: tns, Local: "NumberToWords"}},
+		{name: "Typed element", element: QName{Space: tns, Local: "InvalidNumber"}, wantType: QName{Space: tns, Local: "InvalidNumber"}},
+		{name: "Unknown namespace", element: QName{Local: "NumberToWords"}, wantNil: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := d.Element(tt.element)
+			if (e == nil) != tt.wantNil {
+				t.Fatalf("Element() = %v, wantNil %v", e, tt.wantNil)
+			}
+			if e == nil {
+				return
+			}
+			if e.Type != tt.wantType {
+				t.Errorf("Element().Type = %v, want %v", e.Type, tt.wantType)
+			}
+			if e.Schema == nil || !e.Schema.Qualified() {
+				t.Errorf("Element().Schema = %v, want qualified schema", e.Schema)
+			}
+		})
+	}
+}
+
+func TestDefinitions_ComplexType(t *testing.T) {
+	d := parseTestFile(t, "testdata/numberconversion.wsdl")
+
+	request := d.Element(QName{Space: tns, Local: "NumberToWords"})
+	elements := request.ComplexType.Sequence.Elements
+	if len(elements) != 2 {
+		t.Fatalf("len(Sequence.Elements) = %v, want 2", len(elements))
+	}
+	if got, want := elements[0].Type, (QName{Space: XSDNamespace, Local: "unsignedLong"}); got != want {
+		t.Errorf("Elements[0].Type = %v, want %v", got, want)
+	}
+	if !elements[1].Optional() {
+		t.Errorf("Elements[1].Optional() = false, want true")
+	}
+
+	fault := d.ComplexType(QName{Space: tns, Local: "InvalidNumber"})
+	if fault == nil {
+		t.Fatalf("ComplexType() = nil")
+	}
+	if !fault.Sequence.Elements[1].Repeated() {
+		t.Errorf("Reasons.Repeated() = false, want true")
+	}
+	if len(fault.Attributes) != 1 || fault.Attributes[0].Use != "required" {
+		t.Errorf("Attributes = %v, want required code", fault.Attributes)
+	}
+}
+
+func TestDefinitions_SimpleType(t *testing.T) {
+	d := parseTestFile(t, "testdata/numberconversion.wsdl")
+
+	language := d.SimpleType(QName{Space: tns, Local: "Language"})
+	if language == nil {
+		t.Fatalf("SimpleType() = nil")
+	}
+	if language.Doc != "Language of the words." {
+		t.Errorf("Doc = %q", language.Doc)
+	}
+	var values []string
+	for _, e := range language.Restriction.Enumerations {
+		values = append(values, e.Value)
+	}
+	if len(values) != 2 || values[0] != "en" || values[1] != "es" {
+		t.Errorf("Enumerations = %v, want [en es]", values)
+	}
+}
+
+func TestModelGroup_UnmarshalXML(t *testing.T) {
+	const schema = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:test" targetNamespace="urn:test">
+  <xs:complexType name="Mixed">
+    <xs:sequence minOccurs="0">
+      <xs:element name="first" type="xs:string"/>
+      <xs:choice><xs:element name="a" type="xs:int"/><xs:element name="b" type="xs:int"/></xs:choice>
+      <xs:element name="last" type="tns:Last"/>
+      <xs:any/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	s := &Schema{}
+	if err := newDecoder(strings.NewReader(schema)).Decode(s); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	sequence := s.ComplexTypes[0].Sequence
+	if sequence.MinOccurs != "0" {
+		t.Errorf("MinOccurs = %q, want 0", sequence.MinOccurs)
+	}
+	if len(sequence.Elements) != 2 || len(sequence.Choices) != 1 || len(sequence.Any) != 1 {
+		t.Fatalf("Sequence = %+v, want 2 elements, 1 choice and 1 any", sequence)
+	}
+	particles := sequence.Particles
+	if len(particles) != 4 {
+		t.Fatalf("len(Particles) = %v, want 4", len(particles))
+	}
+	if particles[0].Element == nil || particles[1].Choice == nil || particles[2].Element == nil || particles[3].Any == nil {
+		t.Errorf("Particles = %+v, want element, choice, element, any", particles)
+	}
+	if got, want := particles[2].Element.Type, (QName{Space: "urn:test", Local: "Last"}); got != want {
+		t.Errorf("Particles[2].Element.Type = %v, want %v", got, want)
+	}
+	if len(particles[1].Choice.Elements) != 2 {
+		t.Errorf("Choice.Elements = %v, want 2", particles[1].Choice.Elements)
+	}
+}
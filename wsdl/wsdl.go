@@ -0,0 +1,345 @@
+// Package wsdl parses WSDL 1.1 documents, with their imported WSDLs and XML
+// Schemas, into an in-memory model of services, ports, bindings, operations,
+// messages and schema types.
+//
+//	defs, err := wsdl.ParseFile("numberconversion.wsdl")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, service := range defs.Services {
+//		for _, port := range service.Ports {
+//			fmt.Println(port.Name, port.Address())
+//		}
+//	}
+package wsdl
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Namespaces of the WSDL 1.1 documents and of its SOAP bindings.
+const (
+	Namespace       = "http://schemas.xmlsoap.org/wsdl/"
+	SOAPNamespace   = "http://schemas.xmlsoap.org/wsdl/soap/"
+	SOAP12Namespace = "http://schemas.xmlsoap.org/wsdl/soap12/"
+)
+
+// Definitions struct is a WSDL 1.1 document. Parsing a file merges the
+// definitions of its imported WSDLs and the schemas of its imported XSDs.
+type Definitions struct {
+	XMLName         xml.Name    `xml:"http://schemas.xmlsoap.org/wsdl/ definitions"`
+	Name            string      `xml:"name,attr"`
+	TargetNamespace string      `xml:"targetNamespace,attr"`
+	Doc             string      `xml:"http://schemas.xmlsoap.org/wsdl/ documentation"`
+	Imports         []*Import   `xml:"http://schemas.xmlsoap.org/wsdl/ import"`
+	Types           Types       `xml:"http://schemas.xmlsoap.org/wsdl/ types"`
+	Messages        []*Message  `xml:"http://schemas.xmlsoap.org/wsdl/ message"`
+	PortTypes       []*PortType `xml:"http://schemas.xmlsoap.org/wsdl/ portType"`
+	Bindings        []*Binding  `xml:"http://schemas.xmlsoap.org/wsdl/ binding"`
+	Services        []*Service  `xml:"http://schemas.xmlsoap.org/wsdl/ service"`
+}
+
+// Import struct is a `wsdl:import` of another WSDL document.
+type Import struct {
+	Namespace string `xml:"namespace,attr"`
+	Location  string `xml:"location,attr"`
+}
+
+// Types struct holds the schemas of the WSDL.
+type Types struct {
+	Schemas []*Schema `xml:"http://www.w3.org/2001/XMLSchema schema"`
+}
+
+// Message struct is a `wsdl:message` with its parts.
+type Message struct {
+	Name  string  `xml:"name,attr"`
+	Parts []*Part `xml:"http://schemas.xmlsoap.org/wsdl/ part"`
+
+	// Namespace is the target namespace of the definitions declaring the message.
+	Namespace string `xml:"-"`
+}
+
+// Part struct is a message part, typed by a schema element or type.
+type Part struct {
+	Name    string `xml:"name,attr"`
+	Element QName  `xml:"element,attr"`
+	Type    QName  `xml:"type,attr"`
+}
+
+// PortType struct is a `wsdl:portType`, the abstract interface of a service.
+type PortType struct {
+	Name       string       `xml:"name,attr"`
+	Doc        string       `xml:"http://schemas.xmlsoap.org/wsdl/ documentation"`
+	Operations []*Operation `xml:"http://schemas.xmlsoap.org/wsdl/ operation"`
+
+	Namespace string `xml:"-"`
+}
+
+// Operation struct is an abstract operation of a port type.
+type Operation struct {
+	Name   string       `xml:"name,attr"`
+	Doc    string       `xml:"http://schemas.xmlsoap.org/wsdl/ documentation"`
+	Input  *IOMessage   `xml:"http://schemas.xmlsoap.org/wsdl/ input"`
+	Output *IOMessage   `xml:"http://schemas.xmlsoap.org/wsdl/ output"`
+	Faults []*IOMessage `xml:"http://schemas.xmlsoap.org/wsdl/ fault"`
+}
+
+// IOMessage struct is the input, output or fault message of an operation.
+type IOMessage struct {
+	Name    string `xml:"name,attr"`
+	Message QName  `xml:"message,attr"`
+}
+
+// Binding struct is a `wsdl:binding` of a port type to the SOAP 1.1 or SOAP 1.2 protocol.
+type Binding struct {
+	Name       string              `xml:"name,attr"`
+	Type       QName               `xml:"type,attr"`
+	SOAP       *SOAPBinding        `xml:"http://schemas.xmlsoap.org/wsdl/soap/ binding"`
+	SOAP12     *SOAPBinding        `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ binding"`
+	Operations []*BindingOperation `xml:"http://schemas.xmlsoap.org/wsdl/ operation"`
+
+	Namespace string `xml:"-"`
+}
+
+// SOAPBinding struct is the `soap:binding` of a binding.
+type SOAPBinding struct {
+	Style     string `xml:"style,attr"`
+	Transport string `xml:"transport,attr"`
+}
+
+// Version method returns the SOAP version of the binding, "1.1" or "1.2",
+// or an empty string for a non SOAP binding.
+func (b *Binding) Version() string {
+	switch {
+	case b.SOAP12 != nil:
+		return "1.2"
+	case b.SOAP != nil:
+		return "1.1"
+	}
+	return ""
+}
+
+// Style method returns the default style of the binding operations, "document" if not set.
+func (b *Binding) Style() string {
+	s := b.SOAP
+	if s == nil {
+		s = b.SOAP12
+	}
+	if s == nil || s.Style == "" {
+		return "document"
+	}
+	return s.Style
+}
+
+// BindingOperation struct is the SOAP binding of an operation.
+type BindingOperation struct {
+	Name   string            `xml:"name,attr"`
+	SOAP   *SOAPOperation    `xml:"http://schemas.xmlsoap.org/wsdl/soap/ operation"`
+	SOAP12 *SOAPOperation    `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ operation"`
+	Input  *BindingMessage   `xml:"http://schemas.xmlsoap.org/wsdl/ input"`
+	Output *BindingMessage   `xml:"http://schemas.xmlsoap.org/wsdl/ output"`
+	Faults []*BindingMessage `xml:"http://schemas.xmlsoap.org/wsdl/ fault"`
+}
+
+// SOAPOperation struct is the `soap:operation` of a binding operation.
+type SOAPOperation struct {
+	SOAPAction string `xml:"soapAction,attr"`
+	Style      string `xml:"style,attr"`
+}
+
+// Action method returns the SOAPAction of the operation.
+func (o *BindingOperation) Action() string {
+	switch {
+	case o.SOAP != nil:
+		return o.SOAP.SOAPAction
+	case o.SOAP12 != nil:
+		return o.SOAP12.SOAPAction
+	}
+	return ""
+}
+
+// Style method returns the style of the operation, falling back to the binding style.
+func (o *BindingOperation) Style(b *Binding) string {
+	s := o.SOAP
+	if s == nil {
+		s = o.SOAP12
+	}
+	if s == nil || s.Style == "" {
+		return b.Style()
+	}
+	return s.Style
+}
+
+// BindingMessage struct is the SOAP body, headers or fault of a binding operation message.
+type BindingMessage struct {
+	Name          string        `xml:"name,attr"`
+	SOAPBody      *SOAPBody     `xml:"http://schemas.xmlsoap.org/wsdl/soap/ body"`
+	SOAP12Body    *SOAPBody     `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ body"`
+	SOAPHeaders   []*SOAPHeader `xml:"http://schemas.xmlsoap.org/wsdl/soap/ header"`
+	SOAP12Headers []*SOAPHeader `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ header"`
+	SOAPFault     *SOAPBody     `xml:"http://schemas.xmlsoap.org/wsdl/soap/ fault"`
+	SOAP12Fault   *SOAPBody     `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ fault"`
+}
+
+// SOAPBody struct is the `soap:body` or `soap:fault` of a binding message.
+type SOAPBody struct {
+	Name          string `xml:"name,attr"`
+	Use           string `xml:"use,attr"`
+	Parts         string `xml:"parts,attr"`
+	Namespace     string `xml:"namespace,attr"`
+	EncodingStyle string `xml:"encodingStyle,attr"`
+}
+
+// SOAPHeader struct is a `soap:header` of a binding message.
+type SOAPHeader struct {
+	Message QName  `xml:"message,attr"`
+	Part    string `xml:"part,attr"`
+	Use     string `xml:"use,attr"`
+}
+
+// Body method returns the SOAP 1.1 or SOAP 1.2 body of the message.
+func (m *BindingMessage) Body() *SOAPBody {
+	if m.SOAPBody != nil {
+		return m.SOAPBody
+	}
+	return m.SOAP12Body
+}
+
+// Headers method returns the SOAP 1.1 or SOAP 1.2 headers of the message.
+func (m *BindingMessage) Headers() []*SOAPHeader {
+	return append(append([]*SOAPHeader(nil), m.SOAPHeaders...), m.SOAP12Headers...)
+}
+
+// Service struct is a `wsdl:service` with its ports.
+type Service struct {
+	Name  string  `xml:"name,attr"`
+	Doc   string  `xml:"http://schemas.xmlsoap.org/wsdl/ documentation"`
+	Ports []*Port `xml:"http://schemas.xmlsoap.org/wsdl/ port"`
+
+	Namespace string `xml:"-"`
+}
+
+// Port struct is a `wsdl:port`, a binding exposed at an address.
+type Port struct {
+	Name          string       `xml:"name,attr"`
+	Binding       QName        `xml:"binding,attr"`
+	SOAPAddress   *SOAPAddress `xml:"http://schemas.xmlsoap.org/wsdl/soap/ address"`
+	SOAP12Address *SOAPAddress `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ address"`
+}
+
+// SOAPAddress struct is the `soap:address` of a port.
+type SOAPAddress struct {
+	Location string `xml:"location,attr"`
+}
+
+// Address method returns the endpoint URL of the port.
+func (p *Port) Address() string {
+	switch {
+	case p.SOAPAddress != nil:
+		return p.SOAPAddress.Location
+	case p.SOAP12Address != nil:
+		return p.SOAP12Address.Location
+	}
+	return ""
+}
+
+// Parse method reads a WSDL document. Imports are not resolved, use ParseFile
+// to load the imported WSDL and XSD files.
+func Parse(r io.Reader) (*Definitions, error) {
+	d := &Definitions{}
+	if err := newDecoder(r).Decode(d); err != nil {
+		return nil, err
+	}
+	d.link()
+	return d, nil
+}
+
+// link sets the namespace of the top level declarations.
+func (d *Definitions) link() {
+	for _, m := range d.Messages {
+		m.Namespace = d.TargetNamespace
+	}
+	for _, p := range d.PortTypes {
+		p.Namespace = d.TargetNamespace
+	}
+	for _, b := range d.Bindings {
+		b.Namespace = d.TargetNamespace
+	}
+	for _, s := range d.Services {
+		s.Namespace = d.TargetNamespace
+	}
+	for _, s := range d.Types.Schemas {
+		s.link()
+	}
+}
+
+// Message method returns the message declared with the name, nil if none.
+func (d *Definitions) Message(name QName) *Message {
+	for _, m := range d.Messages {
+		if m.Namespace == name.Space && m.Name == name.Local {
+			return m
+		}
+	}
+	return nil
+}
+
+// PortType method returns the port type declared with the name, nil if none.
+func (d *Definitions) PortType(name QName) *PortType {
+	for _, p := range d.PortTypes {
+		if p.Namespace == name.Space && p.Name == name.Local {
+			return p
+		}
+	}
+	return nil
+}
+
+// Binding method returns the binding declared with the name, nil if none.
+func (d *Definitions) Binding(name QName) *Binding {
+	for _, b := range d.Bindings {
+		if b.Namespace == name.Space && b.Name == name.Local {
+			return b
+		}
+	}
+	return nil
+}
+
+// Service method returns the service with the name, nil if none.
+func (d *Definitions) Service(name string) *Service {
+	for _, s := range d.Services {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Operation method returns the operation of the port type with the name, nil if none.
+func (p *PortType) Operation(name string) *Operation {
+	for _, o := range p.Operations {
+		if o.Name == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// Operation method returns the binding operation with the name, nil if none.
+func (b *Binding) Operation(name string) *BindingOperation {
+	for _, o := range b.Operations {
+		if o.Name == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// Fault method returns the fault message of the operation with the name, nil if none.
+func (o *Operation) Fault(name string) *IOMessage {
+	for _, f := range o.Faults {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
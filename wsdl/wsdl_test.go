@@ -0,0 +1,144 @@
+package wsdl
+
+import (
+	"os"
+	"testing"
+)
+
+const tns = "http://www.dataaccess.com/webservicesserver/"
+
+func parseTestFile(t *testing.T, name string) *Definitions {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, err := Parse(f)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	d := parseTestFile(t, "testdata/numberconversion.wsdl")
+
+	if d.Name != "NumberConversion" || d.TargetNamespace != tns {
+		t.Errorf("Parse() = %v %v", d.Name, d.TargetNamespace)
+	}
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{name: "Schemas", got: len(d.Types.Schemas), want: 1},
+		{name: "Messages", got: len(d.Messages), want: 3},
+		{name: "PortTypes", got: len(d.PortTypes), want: 1},
+		{name: "Bindings", got: len(d.Bindings), want: 2},
+		{name: "Services", got: len(d.Services), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("len(%s) = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_NotWSDL(t *testing.T) {
+	f, err := os.Open("testdata/import/orders.xsd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := Parse(f); err == nil {
+		t.Errorf("Parse() error = nil, want error")
+	}
+}
+
+func TestDefinitions_Service(t *testing.T) {
+	d := parseTestFile(t, "testdata/numberconversion.wsdl")
+
+	service := d.Service("NumberConversion")
+	if service == nil {
+		t.Fatalf("Service() = nil")
+	}
+	tests := []struct {
+		name        string
+		port        *Port
+		wantAddress string
+		wantVersion string
+		wantAction  string
+	}{
+		{
+			name:        "SOAP 1.1 port",
+			port:        service.Ports[0],
+			wantAddress: "https://www.dataaccess.com/webservicesserver/NumberConversion.wso",
+			wantVersion: "1.1",
+			wantAction:  tns + "NumberToWords",
+		},
+		{
+			name:        "SOAP 1.2 port",
+			port:        service.Ports[1],
+			wantAddress: "https://www.dataaccess.com/webservicesserver/NumberConversion.wso",
+			wantVersion: "1.2",
+			wantAction:  tns + "NumberToWords",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.port.Address(); got != tt.wantAddress {
+				t.Errorf("Address() = %v, want %v", got, tt.wantAddress)
+			}
+			binding := d.Binding(tt.port.Binding)
+			if binding == nil {
+				t.Fatalf("Binding(%v) = nil", tt.port.Binding)
+			}
+			if got := binding.Version(); got != tt.wantVersion {
+				t.Errorf("Version() = %v, want %v", got, tt.wantVersion)
+			}
+			op := binding.Operation("NumberToWords")
+			if got := op.Action(); got != tt.wantAction {
+				t.Errorf("Action() = %v, want %v", got, tt.wantAction)
+			}
+			if got := op.Style(binding); got != "document" {
+				t.Errorf("Style() = %v, want document", got)
+			}
+			if got := op.Input.Body().Use; got != "literal" {
+				t.Errorf("Body().Use = %v, want literal", got)
+			}
+		})
+	}
+}
+
+func TestDefinitions_Message(t *testing.T) {
+	d := parseTestFile(t, "testdata/numberconversion.wsdl")
+
+	portType := d.PortType(QName{Space: tns, Local: "NumberConversionSoapType"})
+	if portType == nil {
+		t.Fatalf("PortType() = nil")
+	}
+	op := portType.Operation("NumberToWords")
+	tests := []struct {
+		name        string
+		message     QName
+		wantElement QName
+	}{
+		{name: "Input", message: op.Input.Message, wantElement: QName{Space: tns, Local: "NumberToWords"}},
+		{name: "Output", message: op.Output.Message, wantElement: QName{Space: tns, Local: "NumberToWordsResponse"}},
+		{name: "Fault", message: op.Fault("InvalidNumber").Message, wantElement: QName{Space: tns, Local: "InvalidNumber"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := d.Message(tt.message)
+			if m == nil {
+				t.Fatalf("Message(%v) = nil", tt.message)
+			}
+			if got := m.Parts[0].Element; got != tt.wantElement {
+				t.Errorf("Parts[0].Element = %v, want %v", got, tt.wantElement)
+			}
+		})
+	}
+}